@@ -0,0 +1,147 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+)
+
+type ArchiveFile struct {
+	Path     string `json:"path"`
+	Size     uint64 `json:"size"`
+	Checksum string `json:"sha256"`
+}
+
+type ArchiveComponent struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Files int    `json:"files"`
+	Size  uint64 `json:"size"`
+}
+
+type ArchiveInfo struct {
+	Manifest   map[string]interface{} `json:"manifest"`
+	Components []ArchiveComponent     `json:"components"`
+	Files      []ArchiveFile          `json:"files"`
+	Size       uint64                 `json:"size"`
+	Signed     bool                   `json:"signed"`
+	Signature  string                 `json:"signature,omitempty"`
+}
+
+func readArchiveInfo(path string) *ArchiveInfo {
+	r, err := zip.OpenReader(path)
+	checkErr(err)
+	defer r.Close()
+	info := &ArchiveInfo{}
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if f.Name == "manifest.json" {
+			data, err := readZipEntry(f)
+			checkErr(err)
+			info.Manifest = parseManifest(data).(map[string]interface{})
+		}
+		checksum, err := hashZipEntry(f)
+		checkErr(err)
+		info.Files = append(info.Files, ArchiveFile{Path: f.Name, Size: f.UncompressedSize64, Checksum: checksum})
+		info.Size += f.UncompressedSize64
+	}
+	if info.Manifest == nil {
+		exitErr("Error: archive does not contain manifest.json")
+	}
+	if !hasValidInstalls(info.Manifest) {
+		exitErr("Error: manifest does not have valid installs")
+	}
+	iterateInstallations("", info.Manifest, func(componentPath, componentName, installType string) {
+		component := ArchiveComponent{Type: installType, Name: componentName, Path: filepath.ToSlash(componentPath)}
+		for _, file := range info.Files {
+			if strings.HasPrefix(file.Path, component.Path+"/") {
+				component.Files++
+				component.Size += file.Size
+			}
+		}
+		info.Components = append(info.Components, component)
+	})
+	signature, err := verifyArchive(path, rootOptions.TrustedKeys)
+	switch {
+	case errors.Is(err, errUnsigned):
+	case err != nil:
+		info.Signed = true
+		info.Signature = "invalid: " + err.Error()
+	case signature.Trusted:
+		info.Signed = true
+		info.Signature = "valid, trusted key " + signature.Fingerprint
+	default:
+		info.Signed = true
+		info.Signature = "valid, untrusted key " + signature.Fingerprint
+	}
+	return info
+}
+
+func inspectPlugin(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	info := readArchiveInfo(args.Get(0))
+	if cmd.String("format") == "json" {
+		smartPrint(jsonEncode(info))
+		return nil
+	}
+	fmt.Println("Manifest:")
+	smartPrint(jsonEncode(info.Manifest))
+	if info.Signed {
+		fmt.Println("\nSignature:", info.Signature)
+	} else {
+		fmt.Println("\nSignature: not signed")
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME\tPATH\tFILES\tSIZE")
+	for _, component := range info.Components {
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%d\t%s\n",
+			component.Type,
+			component.Name,
+			component.Path,
+			component.Files,
+			formatSize(int64(component.Size)),
+		)
+	}
+	w.Flush()
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tSIZE\tSHA256")
+	for _, file := range info.Files {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", file.Path, formatSize(int64(file.Size)), file.Checksum)
+	}
+	w.Flush()
+	fmt.Printf("\n%d files, %s total\n", len(info.Files), formatSize(int64(info.Size)))
+	return nil
+}
+
+func unpackPlugin(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 2 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path := args.Get(0)
+	out := args.Get(1)
+	if entries, err := os.ReadDir(out); err == nil && len(entries) > 0 {
+		exitErr(fmt.Sprintf("Error: directory %s is not empty", out))
+	}
+	extractZip(path, out)
+	manifest := readManifest(out).(map[string]interface{})
+	fmt.Printf("Plugin %s %s unpacked to %s\n", pluginKey(manifest), manifest["version"], out)
+	return nil
+}
@@ -277,8 +277,193 @@ func main() {
 						Name:      "init",
 						Action:    initPlugin,
 						Usage:     "Create a new plugin",
-						UsageText: "bitcart-cli plugin init <path>",
+						UsageText: "bitcart-cli plugin init [command options] <path>",
 						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:  "features",
+								Usage: "Extra files to generate (backend: router, models, migrations, settings, requirements, tests; admin, store: tests)",
+							},
+							&cli.StringFlag{
+								Name:  "template",
+								Usage: "Scaffold from a template directory or git repository (url#branch) instead of the defaults",
+							},
+							&cli.StringSliceFlag{
+								Name:  "var",
+								Usage: "Set a template variable (key=value) instead of prompting for it",
+							},
+							&cli.BoolFlag{
+								Name:    "save",
+								Aliases: []string{"s"},
+								Usage:   "Save repository directories to not ask later",
+								Value:   false,
+							},
+						},
+					},
+					{
+						Name:      "add-component",
+						Action:    addComponent,
+						Usage:     "Add a new component to an existing plugin",
+						UsageText: "bitcart-cli plugin add-component [command options] <path>",
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:  "features",
+								Usage: "Extra files to generate (backend: router, models, migrations, settings, requirements, tests; admin, store: tests)",
+							},
+							&cli.StringFlag{
+								Name:  "template",
+								Usage: "Scaffold from a template directory or git repository (url#branch) instead of the defaults",
+							},
+							&cli.StringSliceFlag{
+								Name:  "var",
+								Usage: "Set a template variable (key=value) instead of prompting for it",
+							},
+							&cli.StringFlag{
+								Name:     "type",
+								Aliases:  []string{"t"},
+								Usage:    "Component type (backend, admin, store or docker)",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:    "name",
+								Aliases: []string{"n"},
+								Usage:   "Component name (i.e. name of the subfolder)",
+							},
+							&cli.BoolFlag{
+								Name:  "no-link",
+								Usage: "Do not symlink the component into the configured repository",
+								Value: false,
+							},
+							&cli.BoolFlag{
+								Name:    "save",
+								Aliases: []string{"s"},
+								Usage:   "Save repository directories to not ask later",
+								Value:   false,
+							},
+						},
+					},
+					{
+						Name:      "remove-component",
+						Action:    removeComponent,
+						Usage:     "Remove a component from a plugin",
+						UsageText: "bitcart-cli plugin remove-component [command options] <path>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "type",
+								Aliases:  []string{"t"},
+								Usage:    "Component type (backend, admin, store or docker)",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "name",
+								Aliases:  []string{"n"},
+								Usage:    "Component name (i.e. name of the subfolder)",
+								Required: true,
+							},
+							&cli.BoolFlag{
+								Name:  "keep-files",
+								Usage: "Only remove the component from the manifest, keeping its directory",
+								Value: false,
+							},
+						},
+					},
+					{
+						Name:      "rename-component",
+						Action:    renameComponent,
+						Usage:     "Rename a component of a plugin",
+						UsageText: "bitcart-cli plugin rename-component [command options] <path>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "type",
+								Aliases:  []string{"t"},
+								Usage:    "Component type (backend, admin, store or docker)",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "name",
+								Aliases:  []string{"n"},
+								Usage:    "Component name (i.e. name of the subfolder)",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:     "to",
+								Usage:    "New component name",
+								Required: true,
+							},
+						},
+					},
+					{
+						Name:      "diff",
+						Action:    diffPlugin,
+						Usage:     "Show differences between installed plugin components and a plugin directory or archive",
+						UsageText: "bitcart-cli plugin diff [command options] <path|archive>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "name-only",
+								Usage: "Only list changed files without showing diffs",
+								Value: false,
+							},
+							&cli.BoolFlag{
+								Name:  "exit-code",
+								Usage: "Exit with status 1 if there are differences",
+								Value: false,
+							},
+						},
+					},
+					{
+						Name:      "doctor",
+						Action:    doctorPlugin,
+						Usage:     "Diagnose plugin installations in the configured repositories",
+						UsageText: "bitcart-cli plugin doctor [command options]",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "fix",
+								Usage: "Repair problems where possible",
+								Value: false,
+							},
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"o"},
+								Usage:   "Report format (text, json or sarif)",
+								Value:   "text",
+							},
+						},
+					},
+					{
+						Name:  "generate",
+						Usage: "Generate frontend pages and extension slot components",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "path",
+								Aliases: []string{"p"},
+								Usage:   "Plugin directory",
+								Value:   ".",
+							},
+						},
+						Commands: []*cli.Command{
+							{
+								Name:      "page",
+								Action:    generatePage,
+								Usage:     "Create a page and register it in config/routes.js",
+								UsageText: "bitcart-cli plugin generate page <component> <name>",
+							},
+							{
+								Name:      "slot",
+								Action:    generateSlot,
+								Usage:     "Create a component and register it for a UI extension slot in config/extends.js",
+								UsageText: "bitcart-cli plugin generate slot <component> <slot> <Component>",
+							},
+						},
+					},
+					{
+						Name:      "dev",
+						Action:    devPlugin,
+						Usage:     "Copy plugin components into repositories and keep them in sync on changes",
+						UsageText: "bitcart-cli plugin dev [command options] <path>",
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:  "reload",
+								Usage: "Command to run in the repository after components of a type change (<type>=<command>)",
+							},
 							&cli.BoolFlag{
 								Name:    "save",
 								Aliases: []string{"s"},
@@ -291,14 +476,69 @@ func main() {
 						Name:      "install",
 						Action:    installPlugin,
 						Usage:     "Install a plugin",
-						UsageText: "bitcart-cli plugin install [command options] <path>",
+						UsageText: "bitcart-cli plugin install [command options] <path|archive>",
 						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "Print planned changes without modifying the filesystem",
+								Value: false,
+							},
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"o"},
+								Usage:   "Dry run output format (text or json)",
+								Value:   "text",
+							},
 							&cli.BoolFlag{
 								Name:    "dev",
 								Usage:   "Install in development mode (symlink instead of copying)",
 								Value:   false,
 								Aliases: []string{"D"},
 							},
+							&cli.BoolFlag{
+								Name:    "force",
+								Aliases: []string{"f"},
+								Usage:   "Install even if plugin constraints are not satisfied",
+								Value:   false,
+							},
+							&cli.BoolFlag{
+								Name:  "allow-untrusted",
+								Usage: "Install archives which are unsigned or signed by keys not in trusted_keys",
+								Value: false,
+							},
+							&cli.StringSliceFlag{
+								Name:  "deps",
+								Usage: "Plugin directory, archive or directory of plugins to resolve dependencies from",
+							},
+							&cli.BoolFlag{
+								Name:    "save",
+								Aliases: []string{"s"},
+								Usage:   "Save repository directories to not ask later",
+								Value:   false,
+							},
+						},
+					},
+					{
+						Name:      "upgrade",
+						Action:    upgradePlugin,
+						Usage:     "Upgrade an installed plugin, restoring the previous version on failure",
+						UsageText: "bitcart-cli plugin upgrade [command options] <path|archive>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "force",
+								Aliases: []string{"f"},
+								Usage:   "Reinstall even if the installed version is not older, and ignore constraints",
+								Value:   false,
+							},
+							&cli.BoolFlag{
+								Name:  "allow-untrusted",
+								Usage: "Install archives which are unsigned or signed by keys not in trusted_keys",
+								Value: false,
+							},
+							&cli.StringSliceFlag{
+								Name:  "deps",
+								Usage: "Plugin directory, archive or directory of plugins to resolve dependencies from",
+							},
 							&cli.BoolFlag{
 								Name:    "save",
 								Aliases: []string{"s"},
@@ -311,8 +551,25 @@ func main() {
 						Name:      "uninstall",
 						Action:    uninstallPlugin,
 						Usage:     "Uninstall a plugin",
-						UsageText: "bitcart-cli plugin uninstall <path>",
+						UsageText: "bitcart-cli plugin uninstall [command options] <path|archive>",
 						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "Print planned changes without modifying the filesystem",
+								Value: false,
+							},
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"o"},
+								Usage:   "Dry run output format (text or json)",
+								Value:   "text",
+							},
+							&cli.BoolFlag{
+								Name:    "force",
+								Aliases: []string{"f"},
+								Usage:   "Uninstall even if other installed plugins depend on it",
+								Value:   false,
+							},
 							&cli.BoolFlag{
 								Name:    "save",
 								Aliases: []string{"s"},
@@ -329,9 +586,93 @@ func main() {
 						Flags: []cli.Flag{
 							&cli.StringFlag{
 								Name:  "schema",
-								Usage: "Supply custom schema URL",
+								Usage: "Supply custom schema URL (defaults to $schema of the manifest)",
 								Value: schemaURL,
 							},
+							&cli.BoolFlag{
+								Name:    "force",
+								Aliases: []string{"f"},
+								Usage:   "Only warn if plugin constraints are not satisfied",
+								Value:   false,
+							},
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"o"},
+								Usage:   "Report format (text, json or sarif)",
+								Value:   "text",
+							},
+						},
+					},
+					{
+						Name:      "lint",
+						Action:    lintPlugin,
+						Usage:     "Check plugin formatting, project files and components",
+						UsageText: "bitcart-cli plugin lint [command options] <path>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "fix",
+								Usage: "Automatically fix issues where possible",
+								Value: false,
+							},
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"o"},
+								Usage:   "Report format (text, json or sarif)",
+								Value:   "text",
+							},
+						},
+					},
+					{
+						Name:      "test",
+						Action:    testPlugin,
+						Usage:     "Run component tests against the configured repository checkouts",
+						UsageText: "bitcart-cli plugin test [command options] <path>",
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:    "type",
+								Aliases: []string{"t"},
+								Usage:   "Only run tests of these component types",
+							},
+							&cli.StringFlag{
+								Name:  "python",
+								Usage: "Python interpreter used to run pytest",
+								Value: "python3",
+							},
+							&cli.BoolFlag{
+								Name:    "save",
+								Aliases: []string{"s"},
+								Usage:   "Save repository directories to not ask later",
+								Value:   false,
+							},
+						},
+					},
+					{
+						Name:      "version",
+						Action:    versionPlugin,
+						Usage:     "Bump plugin version in manifest.json and frontend package.json files",
+						UsageText: "bitcart-cli plugin version [command options] <path> <major|minor|patch|version>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "changelog",
+								Usage: "Prepend a CHANGELOG.md entry with git commits since the last tag",
+								Value: false,
+							},
+							&cli.BoolFlag{
+								Name:  "tag",
+								Usage: "Commit the changes and create a git tag",
+								Value: false,
+							},
+							&cli.StringFlag{
+								Name:  "tag-format",
+								Usage: "Tag name, {{name}}, {{author}} and {{version}} are replaced",
+								Value: "v{{version}}",
+							},
+							&cli.BoolFlag{
+								Name:    "force",
+								Aliases: []string{"f"},
+								Usage:   "Allow setting a version which is not newer than the current one",
+								Value:   false,
+							},
 						},
 					},
 					{
@@ -340,11 +681,67 @@ func main() {
 						Usage:     "Package plugin from its directory",
 						UsageText: "bitcart-cli plugin package [command options] <path>",
 						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "Print planned changes without modifying the filesystem",
+								Value: false,
+							},
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"o"},
+								Usage:   "Dry run output format (text or json)",
+								Value:   "text",
+							},
+							&cli.StringFlag{
+								Name:  "output",
+								Usage: "Output directory or package path (defaults to the plugin directory)",
+							},
+							&cli.StringFlag{
+								Name:  "name",
+								Usage: "Package file name template, supports {{name}}, {{author}} and {{version}}",
+								Value: "{{name}}.bitcart",
+							},
 							&cli.BoolFlag{
 								Name:  "no-strip",
-								Usage: "Don't strip unneccesary files from the package (i.e. node_modules)",
+								Usage: "Don't exclude gitignored files from the package (i.e. node_modules)",
 								Value: false,
 							},
+							&cli.StringFlag{
+								Name:  "sign",
+								Usage: "Sign the package with the ed25519 private key at this path (OpenSSH or PKCS#8 format)",
+							},
+						},
+					},
+					{
+						Name:      "inspect",
+						Action:    inspectPlugin,
+						Usage:     "Show manifest, components and files of a plugin package",
+						UsageText: "bitcart-cli plugin inspect [command options] <archive>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"o"},
+								Usage:   "Output format (text or json)",
+								Value:   "text",
+							},
+						},
+					},
+					{
+						Name:      "unpack",
+						Action:    unpackPlugin,
+						Usage:     "Extract a plugin package to a directory",
+						UsageText: "bitcart-cli plugin unpack <archive> <dir>",
+					},
+					{
+						Name:      "verify",
+						Action:    verifyPlugin,
+						Usage:     "Verify plugin package signature against trusted keys",
+						UsageText: "bitcart-cli plugin verify [command options] <archive>",
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{
+								Name:  "key",
+								Usage: "Additional trusted public key in authorized_keys format (i.e. ssh-ed25519 AAAA...)",
+							},
 						},
 					},
 				},
@@ -2,10 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -85,180 +86,26 @@ func initPlugin(ctx context.Context, cmd *cli.Command) error {
 	path, err := filepath.Abs(path)
 	createIfNotExists(path, os.ModePerm)
 	checkErr(err)
+	loadTemplate(cmd.String("template"), cmd.StringSlice("var"))
 	answers := BasicCreatePluginAnswers{}
 	checkErr(survey.Ask(basicPluginCreate, &answers))
-	if slices.Contains(answers.ComponentTypes, "backend") {
-		backendPath := getComponentConfigEntry("backend")
-		var componentName string
-		checkErr(
-			survey.AskOne(
-				&survey.Input{
-					Message: "Enter name of your backend component (i.e. name of the subfolder)",
-				},
-				&componentName,
-				survey.WithValidator(survey.Required),
-			),
-		)
-		if *backendPath == "" {
-			checkErr(
-				survey.AskOne(
-					&survey.Input{Message: "Enter the path to cloned bitcart repository"},
-					backendPath,
-					survey.WithValidator(survey.Required),
-					survey.WithValidator(directoryValidator),
-					survey.WithValidator(backendDirectoryValidator),
-				),
-			)
-		}
-		*backendPath, err = filepath.Abs(*backendPath)
-		checkErr(err)
-		internalPath := filepath.Join(path, "src/backend/"+componentName)
-		answers.FinalTypes = append(
-			answers.FinalTypes,
-			ComponentType{Type: "backend", Path: "src/backend/" + componentName},
-		)
-		data := struct {
-			Name string
-		}{Name: componentName}
-		createIfNotExists(internalPath, os.ModePerm)
-		checkErr(
-			os.WriteFile(
-				filepath.Join(internalPath, "plugin.py"),
-				executeTemplate("plugin/src/backend/plugin.py.tmpl", data, false),
-				os.ModePerm,
-			),
-		)
-		createInitPyFile(internalPath)
-		safeSymlink(
-			internalPath,
-			filepath.Join(
-				*backendPath,
-				getOutputDirectory("backend", answers.Author, componentName),
-			),
-		)
-	}
-	if slices.Contains(answers.ComponentTypes, "docker") {
-		dockerPath := getComponentConfigEntry("docker")
-		var componentName string
-		checkErr(
-			survey.AskOne(
-				&survey.Input{
-					Message: "Enter name of your docker component (i.e. name of the subfolder)",
-				},
-				&componentName,
-				survey.WithValidator(survey.Required),
-			),
-		)
-		if *dockerPath == "" {
-			checkErr(
-				survey.AskOne(
-					&survey.Input{Message: "Enter the path to cloned bitcart-docker repository"},
-					dockerPath,
-					survey.WithValidator(survey.Required),
-					survey.WithValidator(directoryValidator),
-					survey.WithValidator(dockerDirectoryValidator),
-				),
-			)
-		}
-		*dockerPath, err = filepath.Abs(*dockerPath)
-		checkErr(err)
-		internalPath := filepath.Join(path, "src/docker/"+componentName)
-		answers.FinalTypes = append(
-			answers.FinalTypes,
-			ComponentType{Type: "docker", Path: "src/docker/" + componentName},
-		)
-		createIfNotExists(internalPath, os.ModePerm)
-		safeSymlink(
-			internalPath,
-			filepath.Join(
-				*dockerPath,
-				getOutputDirectory("docker", answers.Author, componentName),
-			),
-		)
-	}
-	for _, componentType := range []string{"admin", "store"} {
+	askTemplatePrompts()
+	data := TemplateData{Author: answers.Author, Name: answers.Name, Plugin: answers.Name, Description: answers.Description}
+	for _, componentType := range []string{"backend", "docker", "admin", "store"} {
 		if slices.Contains(answers.ComponentTypes, componentType) {
-			frontendPath := getComponentConfigEntry(componentType)
-			var componentName string
-			checkErr(
-				survey.AskOne(
-					&survey.Input{
-						Message: fmt.Sprintf(
-							"Enter name of your %s component (i.e. name of the subfolder)",
-							componentType,
-						),
-					},
-					&componentName,
-					survey.WithValidator(survey.Required),
-				),
-			)
-			if *frontendPath == "" {
-				checkErr(
-					survey.AskOne(
-						&survey.Input{
-							Message: fmt.Sprintf(
-								"Enter the path to cloned %s frontend repository",
-								componentType,
-							),
-						},
-						frontendPath,
-						survey.WithValidator(survey.Required),
-						survey.WithValidator(directoryValidator),
-						survey.WithValidator(frontendDirectoryValidator),
-					),
-				)
-			}
-			*frontendPath, err = filepath.Abs(*frontendPath)
-			checkErr(err)
-			answers.FinalTypes = append(
-				answers.FinalTypes,
-				ComponentType{
-					Type: componentType,
-					Path: "src/" + componentType + "/" + componentName,
-				},
-			)
-			internalPath := filepath.Join(path, "src/"+componentType+"/"+componentName)
-			createIfNotExists(internalPath, os.ModePerm)
-			createIfNotExists(filepath.Join(internalPath, "config"), os.ModePerm)
-			for _, file := range []string{"index.js", "config/extends.js", "config/routes.js"} {
-				copyFileContents(
-					filepath.Join("plugin/src/frontend", file),
-					filepath.Join(internalPath, file),
-				)
-			}
-			data := struct {
-				Author string
-				Name   string
-			}{Author: answers.Author, Name: componentName}
-			checkErr(
-				os.WriteFile(
-					filepath.Join(internalPath, "package.json"),
-					executeTemplate("plugin/src/frontend/package.json.tmpl", data, false),
-					os.ModePerm,
-				),
-			)
-			checkErr(
-				os.WriteFile(
-					filepath.Join(internalPath, "config/index.js"),
-					executeTemplate("plugin/src/frontend/config/index.js.tmpl", data, false),
-					os.ModePerm,
-				),
-			)
-			safeSymlink(
-				internalPath,
-				filepath.Join(
-					*frontendPath,
-					getOutputDirectory(componentType, answers.Author, componentName),
-				),
-			)
+			componentName := askComponentName(componentType)
+			features := askComponentFeatures(cmd, componentType)
+			component := scaffoldComponent(path, answers.Author, componentType, componentName, features)
+			answers.FinalTypes = append(answers.FinalTypes, component)
+			componentTemplateData := data
+			componentTemplateData.Name, componentTemplateData.Type = componentName, componentType
+			renderTemplateFiles(path, componentTemplateData)
+			linkDevComponent(filepath.Join(path, component.Path), answers.Author, componentType, componentName)
 		}
 	}
-	checkErr(
-		os.WriteFile(
-			filepath.Join(path, "manifest.json"),
-			executeTemplate("plugin/manifest.json.tmpl", answers, true),
-			os.ModePerm,
-		),
+	writeManifest(
+		path,
+		parseManifest(executeTemplate("plugin/manifest.json.tmpl", answers, true)).(map[string]interface{}),
 	)
 	checkErr(
 		os.WriteFile(
@@ -268,6 +115,7 @@ func initPlugin(ctx context.Context, cmd *cli.Command) error {
 		),
 	)
 	copyFileContents("plugin/.editorconfig", filepath.Join(path, ".editorconfig"))
+	renderTemplateFiles(path, data)
 	if save {
 		rootOptions.WriteToDisk()
 	}
@@ -277,37 +125,92 @@ func initPlugin(ctx context.Context, cmd *cli.Command) error {
 
 type pluginMoveAction func(string, string)
 
-func pluginActionBase(path string, save bool, fn pluginMoveAction) {
+func askComponentDirectory(installType string) string {
+	toSave := getComponentConfigEntry(installType)
+	if *toSave == "" {
+		checkErr(survey.AskOne(&survey.Input{
+			Message: fmt.Sprintf(
+				"Enter the path to cloned %s repository",
+				componentData[installType].(map[string]interface{})["name"].(string),
+			),
+		}, toSave, survey.WithValidator(survey.Required), survey.WithValidator(directoryValidator), componentData[installType].(map[string]interface{})["validator"].(survey.AskOpt)))
+	}
+	return *toSave
+}
+
+type PluginComponent struct {
+	Source     string
+	Name       string
+	Type       string
+	Repository string
+	Final      string
+}
+
+func resolvePluginComponents(path string, manifest map[string]interface{}) []PluginComponent {
+	var components []PluginComponent
+	iterateInstallations(path, manifest, func(componentPath, componentName, installType string) {
+		repoPath := askComponentDirectory(installType)
+		components = append(components, PluginComponent{
+			Source:     componentPath,
+			Name:       componentName,
+			Type:       installType,
+			Repository: repoPath,
+			Final: filepath.Join(
+				repoPath,
+				getOutputDirectory(installType, manifest["author"].(string), componentName),
+			),
+		})
+	})
+	return components
+}
+
+func pluginActionBase(path string, save bool, fn pluginMoveAction) []InstalledComponent {
 	path, err := filepath.Abs(path)
 	checkErr(err)
 	manifest := readManifest(path).(map[string]interface{})
-	iterateInstallations(path, manifest, func(componentPath, componentName, installType string) {
-		toSave := getComponentConfigEntry(installType)
-		if *toSave == "" {
-			checkErr(survey.AskOne(&survey.Input{
-				Message: fmt.Sprintf(
-					"Enter the path to cloned %s repository",
-					componentData[installType].(map[string]interface{})["name"].(string),
-				),
-			}, toSave, survey.WithValidator(survey.Required), survey.WithValidator(directoryValidator), componentData[installType].(map[string]interface{})["validator"].(survey.AskOpt)))
-		}
-		finalPath := filepath.Join(
-			*toSave,
-			getOutputDirectory(installType, manifest["author"].(string), componentName),
-		)
+	var installed []InstalledComponent
+	for _, component := range resolvePluginComponents(path, manifest) {
 		var orgPath string
-		if installType == "backend" {
-			orgPath = filepath.Join(*toSave, "modules", manifest["author"].(string))
+		if component.Type == "backend" {
+			orgPath = filepath.Join(component.Repository, "modules", manifest["author"].(string))
 			createInitPyFile(orgPath)
 		}
-		fn(componentPath, finalPath)
-		if installType == "backend" {
+		fn(component.Source, component.Final)
+		if component.Type == "backend" {
 			removeOrgInitIfNoPlugins(orgPath)
 		}
-	})
+		installed = append(
+			installed,
+			InstalledComponent{Type: component.Type, Name: component.Name, Path: component.Final},
+		)
+	}
 	if save {
 		rootOptions.WriteToDisk()
 	}
+	return installed
+}
+
+func installSinglePlugin(source *PluginSource, isDev bool, save bool, force bool) *InstalledPlugin {
+	if isDev && source.Path != source.Origin {
+		exitErr("Error: development mode requires a plugin directory, not an archive")
+	}
+	manifest := source.Manifest
+	enforceBitcartConstraint(manifest, force)
+	components := installComponents(source, isDev, save, nil)
+	return newInstalledPlugin(source, isDev, components)
+}
+
+func newInstalledPlugin(source *PluginSource, isDev bool, components []InstalledComponent) *InstalledPlugin {
+	version, _ := source.Manifest["version"].(string)
+	return &InstalledPlugin{
+		Name:         source.Manifest["name"].(string),
+		Author:       source.Manifest["author"].(string),
+		Version:      version,
+		Source:       source.Origin,
+		Dev:          isDev,
+		Dependencies: getDependencies(source.Manifest),
+		Components:   components,
+	}
 }
 
 func installPlugin(ctx context.Context, cmd *cli.Command) error {
@@ -318,14 +221,28 @@ func installPlugin(ctx context.Context, cmd *cli.Command) error {
 	path := args.Get(0)
 	isDev := cmd.Bool("dev") || args.Get(1) == "--dev" || args.Get(1) == "-D"
 	save := cmd.Bool("save")
-	pluginActionBase(path, save, func(componentPath, finalPath string) {
-		checkErr(os.RemoveAll(finalPath))
-		if !isDev {
-			copyDirectory(componentPath, finalPath)
-		} else {
-			safeSymlink(componentPath, finalPath)
+	source, cleanup := openPluginSource(path)
+	defer cleanup()
+	available, cleanupDependencies := collectPluginSources(cmd.StringSlice("deps"))
+	defer cleanupDependencies()
+	registry := &PluginRegistry{}
+	registry.Load()
+	order, err := resolveInstallOrder(source, available, registry)
+	checkErr(err)
+	verifyPluginSources(order, cmd.Bool("allow-untrusted"))
+	checkDependentConstraints(order, registry, cmd.Bool("force"))
+	if cmd.Bool("dry-run") {
+		printPlan(planInstall(order, source, isDev), cmd.String("format"))
+		return nil
+	}
+	for _, plugin := range order {
+		key := pluginKey(plugin.Manifest)
+		if plugin != source {
+			fmt.Println("Installing dependency", key)
 		}
-	})
+		registry.Plugins[key] = installSinglePlugin(plugin, isDev && plugin == source, save, cmd.Bool("force"))
+		registry.WriteToDisk()
+	}
 	return nil
 }
 
@@ -336,9 +253,28 @@ func uninstallPlugin(ctx context.Context, cmd *cli.Command) error {
 	}
 	path := args.Get(0)
 	save := cmd.Bool("save")
-	pluginActionBase(path, save, func(componentPath, finalPath string) {
+	source, cleanup := openPluginSource(path)
+	defer cleanup()
+	if cmd.Bool("dry-run") {
+		printPlan(planUninstall(source), cmd.String("format"))
+		return nil
+	}
+	key := pluginKey(source.Manifest)
+	registry := &PluginRegistry{}
+	registry.Load()
+	if dependents := registry.Dependents(key); len(dependents) > 0 {
+		message := fmt.Sprintf(
+			"%s is required by %s",
+			key,
+			strings.Join(dependents, ", "),
+		)
+		failUnlessForced(message, "use --force to ignore", cmd.Bool("force"))
+	}
+	pluginActionBase(source.Path, save, func(componentPath, finalPath string) {
 		checkErr(os.RemoveAll(finalPath))
 	})
+	delete(registry.Plugins, key)
+	registry.WriteToDisk()
 	return nil
 }
 
@@ -348,39 +284,58 @@ func validatePlugin(ctx context.Context, cmd *cli.Command) error {
 		return cli.ShowSubcommandHelp(cmd)
 	}
 	path := args.Get(0)
+	manifest := readManifest(path)
 	url := args.Get(2) // after --schema part
 	if url == "" {
 		url = cmd.String("schema")
+		if manifestSchema, ok := manifest.(map[string]interface{})["$schema"].(string); ok && !cmd.IsSet("schema") {
+			url = manifestSchema
+		}
 	}
 	sch := prepareSchema(url)
-	manifest := readManifest(path)
+	report := &ValidationReport{Path: path}
 	if err := sch.Validate(manifest); err != nil {
-		log.Fatalf("%#v", err)
+		report.addSchemaErrors(err)
 	}
-	iterateInstallations(
-		path,
-		manifest.(map[string]interface{}),
-		func(componentPath, componentName, installType string) {
+	manifestData := manifest.(map[string]interface{})
+	validateBitcartConstraint(manifestData, report, cmd.Bool("force"))
+	if hasValidInstalls(manifestData) && validateInstallPaths(report, path, manifestData) {
+		author, _ := manifestData["author"].(string)
+		index := 0
+		iterateInstallations(path, manifestData, func(componentPath, componentName, installType string) {
+			location := fmt.Sprintf("/installs/%d", index)
+			index++
 			switch installType {
 			case "backend":
-				pluginBase := filepath.Join(componentPath, "plugin.py")
-				if !validateFileExists(pluginBase) {
-					log.Fatalf(
-						"Plugin's backend component %s does not include plugin.py",
-						componentPath,
-					)
-				}
-			case "admin":
-				validateFrontend("admin", componentPath)
-			case "store":
-				validateFrontend("store", componentPath)
+				validateBackend(report, path, componentPath, location)
+				validateBackendSemantics(report, path, componentPath, componentName)
+			case "admin", "store":
+				validateFrontend(report, path, installType, componentPath, location)
+				validateFrontendSemantics(report, path, componentPath, author, componentName)
+			case "docker":
+				validateDocker(report, path, componentPath, location, author, componentName)
 			}
-		},
-	)
-	fmt.Println("Plugin is valid!")
+		})
+	}
+	report.Print(cmd.String("format"))
 	return nil
 }
 
+// getPackageOutputPath expands {{name}}, {{author}} and {{version}} placeholders. Output can be either
+// a directory to put the package into, or the full path of the package
+func getPackageOutputPath(path string, manifest map[string]interface{}, output string, nameTemplate string) string {
+	version := fmt.Sprint(manifest["version"])
+	name := expandManifestTemplate(manifest, version, nameTemplate)
+	if output == "" {
+		return filepath.Join(path, name)
+	}
+	output = expandManifestTemplate(manifest, version, output)
+	if isDir(output) || strings.HasSuffix(output, "/") || strings.HasSuffix(output, string(filepath.Separator)) {
+		return filepath.Join(output, name)
+	}
+	return output
+}
+
 func packagePlugin(ctx context.Context, cmd *cli.Command) error {
 	args := cmd.Args()
 	if args.Len() < 1 {
@@ -389,22 +344,18 @@ func packagePlugin(ctx context.Context, cmd *cli.Command) error {
 	path := args.Get(0)
 	manifest := readManifest(path).(map[string]interface{})
 	noStrip := cmd.Bool("no-strip") || args.Get(1) == "--no-strip"
-	checkExcludeGitignore, err := rejectGitignored([]string{path})
-	checkErr(err)
-	if !noStrip {
-		walker := func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
-			if checkExcludeGitignore(path) {
-				return os.RemoveAll(path)
-			}
-			return nil
-		}
-		checkErr(filepath.Walk(path, walker))
+	exclude := packageExcluder(path, manifest, noStrip)
+	outPath := getPackageOutputPath(path, manifest, cmd.String("output"), cmd.String("name"))
+	if cmd.Bool("dry-run") {
+		printPlan(planPackage(path, outPath, exclude), cmd.String("format"))
+		return nil
+	}
+	var key ed25519.PrivateKey
+	if keyPath := cmd.String("sign"); keyPath != "" {
+		key = loadSigningKey(keyPath)
 	}
-	outPath := filepath.Join(path, manifest["name"].(string)+".bitcart")
-	createZip(path, outPath)
+	createIfNotExists(filepath.Dir(outPath), os.ModePerm)
+	createZip(path, outPath, exclude, key)
 	fmt.Println("Plugin packaged to", outPath)
 	return nil
 }
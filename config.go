@@ -4,6 +4,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,13 +12,14 @@ import (
 )
 
 type Config struct {
-	BitcartDirectory       string `yaml:"bitcart_directory"`
-	BitcartAdminDirectory  string `yaml:"bitcart_admin_directory"`
-	BitcartStoreDirectory  string `yaml:"bitcart_store_directory"`
-	BitcartDockerDirectory string `yaml:"bitcart_docker_directory"`
-	GitHubAPI              string `yaml:"-"`
-	SkipUpdateCheck        bool   `yaml:"-"`
-	FileUsed               string `yaml:"-"`
+	BitcartDirectory       string   `yaml:"bitcart_directory"`
+	BitcartAdminDirectory  string   `yaml:"bitcart_admin_directory"`
+	BitcartStoreDirectory  string   `yaml:"bitcart_store_directory"`
+	BitcartDockerDirectory string   `yaml:"bitcart_docker_directory"`
+	TrustedKeys            []string `yaml:"trusted_keys,omitempty"`
+	GitHubAPI              string   `yaml:"-"`
+	SkipUpdateCheck        bool     `yaml:"-"`
+	FileUsed               string   `yaml:"-"`
 }
 
 type UpdateCheck struct {
@@ -40,6 +42,56 @@ func (upd *UpdateCheck) WriteToDisk() {
 	checkErr(os.WriteFile(upd.FileUsed, enc, 0600))
 }
 
+type InstalledComponent struct {
+	Type string `yaml:"type"`
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+type InstalledPlugin struct {
+	Name         string               `yaml:"name"`
+	Author       string               `yaml:"author"`
+	Version      string               `yaml:"version"`
+	Source       string               `yaml:"source"`
+	Dev          bool                 `yaml:"dev"`
+	Dependencies map[string]string    `yaml:"dependencies,omitempty"`
+	Components   []InstalledComponent `yaml:"components"`
+}
+
+type PluginRegistry struct {
+	Plugins  map[string]*InstalledPlugin `yaml:"plugins"`
+	FileUsed string                      `yaml:"-"`
+}
+
+func (reg *PluginRegistry) Load() {
+	path := filepath.Join(SettingsPath(), pluginsFilename())
+	ensureSettingsFileExists(path)
+	reg.FileUsed = path
+	content, err := os.ReadFile(path)
+	checkErr(err)
+	checkErr(yaml.Unmarshal(content, &reg))
+	if reg.Plugins == nil {
+		reg.Plugins = map[string]*InstalledPlugin{}
+	}
+}
+
+func (reg *PluginRegistry) WriteToDisk() {
+	enc, err := yaml.Marshal(&reg)
+	checkErr(err)
+	checkErr(os.WriteFile(reg.FileUsed, enc, 0600))
+}
+
+func (reg *PluginRegistry) Dependents(key string) []string {
+	var dependents []string
+	for name, plugin := range reg.Plugins {
+		if _, ok := plugin.Dependencies[key]; ok {
+			dependents = append(dependents, name)
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
 func (cfg *Config) Load() {
 	cfg.LoadFromDisk()
 	cfg.LoadFromEnv("bitcart_cli")
@@ -78,6 +130,10 @@ func updateCheckFilename() string {
 	return "update_check.yml"
 }
 
+func pluginsFilename() string {
+	return "plugins.yml"
+}
+
 func configFilename() string {
 	return "config.yml"
 }
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+var bitcartVersionRegex = regexp.MustCompile(`(?m)^VERSION\s*=\s*["']([^"']+)["']`)
+
+// parseBitcartVersion converts Bitcart's 4-part versions (i.e. 0.9.1.0) to semver
+func parseBitcartVersion(version string) (semver.Version, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	return semver.ParseTolerant(strings.Join(parts, "."))
+}
+
+func getBitcartVersion(backendPath string) (semver.Version, error) {
+	data, err := os.ReadFile(filepath.Join(backendPath, "api", "version.py"))
+	if err != nil {
+		return semver.Version{}, err
+	}
+	match := bitcartVersionRegex.FindSubmatch(data)
+	if match == nil {
+		return semver.Version{}, errors.New("Could not find VERSION in api/version.py")
+	}
+	return parseBitcartVersion(string(match[1]))
+}
+
+func getBitcartConstraint(manifest map[string]interface{}) string {
+	constraints, ok := manifest["constraints"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	constraint, _ := constraints["bitcart"].(string)
+	return strings.TrimSpace(constraint)
+}
+
+func checkVersionConstraint(constraint string, version semver.Version) (bool, error) {
+	versionRange, err := semver.ParseRange(constraint)
+	if err != nil {
+		return false, fmt.Errorf("Invalid version constraint %q: %w", constraint, err)
+	}
+	return versionRange(version), nil
+}
+
+// checkBitcartConstraint refuses to continue if the backend repository doesn't satisfy plugin constraints.
+// With force set, only a warning is printed
+func checkBitcartConstraint(manifest map[string]interface{}, backendPath string, force bool) {
+	constraint := getBitcartConstraint(manifest)
+	if constraint == "" {
+		return
+	}
+	version, err := getBitcartVersion(backendPath)
+	if err != nil {
+		failUnlessForced(
+			fmt.Sprintf("Could not detect Bitcart version of %s: %s", backendPath, err),
+			"use --force to ignore",
+			force,
+		)
+		return
+	}
+	ok, err := checkVersionConstraint(constraint, version)
+	checkErr(err)
+	if !ok {
+		failUnlessForced(
+			fmt.Sprintf(
+				"Plugin %s requires Bitcart %s, but %s has version %s",
+				manifest["name"],
+				constraint,
+				backendPath,
+				version,
+			),
+			"use --force to ignore",
+			force,
+		)
+	}
+}
+
+// enforceBitcartConstraint checks the constraint before installing. The bitcart repository is only asked for
+// if the plugin installs a backend component, otherwise the check is skipped when it is not configured
+func enforceBitcartConstraint(manifest map[string]interface{}, force bool) {
+	if getBitcartConstraint(manifest) == "" {
+		return
+	}
+	backendPath := *getComponentConfigEntry("backend")
+	if backendPath == "" && hasBackendComponent(manifest) {
+		backendPath = askComponentDirectory("backend")
+	}
+	if backendPath == "" {
+		fmt.Printf(
+			"Warning: Bitcart repository is not configured, version constraint of %s was not checked\n",
+			pluginKey(manifest),
+		)
+		return
+	}
+	checkBitcartConstraint(manifest, backendPath, force)
+}
+
+func hasBackendComponent(manifest map[string]interface{}) bool {
+	found := false
+	if hasValidInstalls(manifest) {
+		iterateInstallations("", manifest, func(componentPath, componentName, installType string) {
+			found = found || installType == "backend"
+		})
+	}
+	return found
+}
+
+// validateBitcartConstraint checks the constraint syntax and, if the bitcart repository is configured,
+// whether it is satisfied. With force set, unsatisfied constraints are reported as warnings
+func validateBitcartConstraint(manifest map[string]interface{}, report *ValidationReport, force bool) {
+	constraint := getBitcartConstraint(manifest)
+	if constraint == "" {
+		return
+	}
+	location := "/constraints/bitcart"
+	if _, err := checkVersionConstraint(constraint, semver.Version{}); err != nil {
+		report.Error(
+			"constraint",
+			"manifest.json",
+			location,
+			err.Error(),
+			"Use a semver range, i.e. >=0.9.0 <1.0.0",
+		)
+		return
+	}
+	backendPath := *getComponentConfigEntry("backend")
+	if backendPath == "" {
+		report.Warning(
+			"constraint",
+			"manifest.json",
+			location,
+			"Bitcart repository is not configured, version constraint was not checked",
+			"Configure bitcart_directory in the CLI config to check constraints",
+		)
+		return
+	}
+	add := report.Error
+	if force {
+		add = report.Warning
+	}
+	version, err := getBitcartVersion(backendPath)
+	if err != nil {
+		add(
+			"constraint",
+			"manifest.json",
+			location,
+			fmt.Sprintf("Could not detect Bitcart version of %s: %s", backendPath, err),
+			"Check that bitcart_directory points to a bitcart repository",
+		)
+		return
+	}
+	if ok, _ := checkVersionConstraint(constraint, version); !ok {
+		add(
+			"constraint",
+			"manifest.json",
+			location,
+			fmt.Sprintf("Plugin requires Bitcart %s, but %s has version %s", constraint, backendPath, version),
+			"Update the Bitcart checkout or relax the constraint",
+		)
+	}
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"bytes"
 	"embed"
+	"io/fs"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -12,7 +14,11 @@ import (
 //go:embed plugin/*
 var pluginsData embed.FS
 
-var schemaURL = "https://bitcart.ai/schemas/plugin/1.3.0/plugin.schema.json"
+// templatesFS is used for scaffolding, it can be replaced by a custom template set falling back to pluginsData
+var templatesFS fs.FS = pluginsData
+
+var schemaBaseURL = "https://bitcart.ai/schemas/plugin/"
+var schemaURL = schemaBaseURL + "1.3.0/plugin.schema.json"
 var envFile = "conf/.env"
 
 var COINS = map[string]string{
@@ -72,15 +78,19 @@ var basicPluginCreate = []*survey.Question{
 }
 
 func copyFileContents(src, dst string) {
-	fileContent, err := pluginsData.ReadFile(src)
+	fileContent, err := fs.ReadFile(templatesFS, src)
 	checkErr(err)
 	copyData(fileContent, dst)
 }
 
+var templateFuncs = template.FuncMap{
+	"IsLast": func(i, size int) bool { return i == size-1 },
+	"var":    func(name string) interface{} { return templateVars[name] },
+	"upper":  strings.ToUpper,
+}
+
 func executeTemplate(templatePath string, data interface{}, stripSpaces bool) []byte {
-	tmpl, err := template.New(filepath.Base(templatePath)).Funcs(template.FuncMap{
-		"IsLast": func(i, size int) bool { return i == size-1 },
-	}).ParseFS(pluginsData, templatePath)
+	tmpl, err := template.New(filepath.Base(templatePath)).Funcs(templateFuncs).ParseFS(templatesFS, templatePath)
 	checkErr(err)
 	buf := new(bytes.Buffer)
 	checkErr(tmpl.ExecuteTemplate(buf, filepath.Base(templatePath), &data))
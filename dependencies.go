@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+type PluginSource struct {
+	Path     string
+	Origin   string
+	Manifest map[string]interface{}
+}
+
+func pluginKey(manifest map[string]interface{}) string {
+	author, _ := manifest["author"].(string)
+	name, _ := manifest["name"].(string)
+	return author + "/" + name
+}
+
+func getDependencies(manifest map[string]interface{}) map[string]string {
+	result := map[string]string{}
+	dependencies, ok := manifest["dependencies"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+	for key, value := range dependencies {
+		constraint, _ := value.(string)
+		result[key] = strings.TrimSpace(constraint)
+	}
+	return result
+}
+
+func sortedKeys[T any](data map[string]T) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func satisfiesConstraint(version string, constraint string) (bool, error) {
+	if constraint == "" || constraint == "*" {
+		return true, nil
+	}
+	parsed, err := semver.ParseTolerant(version)
+	if err != nil {
+		return false, fmt.Errorf("Invalid version %q: %w", version, err)
+	}
+	return checkVersionConstraint(constraint, parsed)
+}
+
+func isArchive(path string) bool {
+	statResult, err := os.Stat(path)
+	return err == nil && statResult.Mode().IsRegular()
+}
+
+// openPluginSource loads a plugin from a directory or a packaged archive.
+// Archives are extracted to a temporary directory which is removed by calling cleanup
+func openPluginSource(path string) (*PluginSource, func()) {
+	path, err := filepath.Abs(path)
+	checkErr(err)
+	cleanup := func() {}
+	dir := path
+	if isArchive(path) {
+		dir, err = os.MkdirTemp("", "bitcart-plugin-")
+		checkErr(err)
+		cleanup = func() { os.RemoveAll(dir) }
+		extractZip(path, dir)
+	}
+	manifest := readManifest(dir).(map[string]interface{})
+	return &PluginSource{Path: dir, Origin: path, Manifest: manifest}, cleanup
+}
+
+// collectPluginSources finds plugins in the search paths. Each path can be a plugin directory, an archive,
+// or a directory containing plugin directories and archives
+func collectPluginSources(searchPaths []string) (map[string][]*PluginSource, func()) {
+	sources := map[string][]*PluginSource{}
+	var cleanups []func()
+	add := func(path string) {
+		source, cleanup := openPluginSource(path)
+		cleanups = append(cleanups, cleanup)
+		key := pluginKey(source.Manifest)
+		sources[key] = append(sources[key], source)
+	}
+	for _, searchPath := range searchPaths {
+		if isArchive(searchPath) || exists(filepath.Join(searchPath, "manifest.json")) {
+			add(searchPath)
+			continue
+		}
+		entries, err := os.ReadDir(searchPath)
+		checkErr(err)
+		for _, entry := range entries {
+			entryPath := filepath.Join(searchPath, entry.Name())
+			if (entry.IsDir() && exists(filepath.Join(entryPath, "manifest.json"))) ||
+				(!entry.IsDir() && strings.HasSuffix(entry.Name(), ".bitcart")) {
+				add(entryPath)
+			}
+		}
+	}
+	return sources, func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}
+}
+
+func pickPluginSource(candidates []*PluginSource, constraint string) *PluginSource {
+	var best *PluginSource
+	var bestVersion semver.Version
+	for _, candidate := range candidates {
+		version, _ := candidate.Manifest["version"].(string)
+		if ok, err := satisfiesConstraint(version, constraint); err != nil || !ok {
+			continue
+		}
+		parsed, _ := semver.ParseTolerant(version)
+		if best == nil || parsed.GT(bestVersion) {
+			best = candidate
+			bestVersion = parsed
+		}
+	}
+	return best
+}
+
+// resolveInstallOrder returns plugins to install in topological order, dependencies first.
+// Dependencies already installed in a compatible version are skipped
+func resolveInstallOrder(
+	root *PluginSource,
+	available map[string][]*PluginSource,
+	registry *PluginRegistry,
+) ([]*PluginSource, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[string]int{}
+	chosen := map[string]*PluginSource{}
+	var order []*PluginSource
+	// installed dependencies are not visited again, but their recorded dependencies can still lead back
+	// to plugins being installed
+	var checkInstalled func(key string, stack []string) error
+	checkInstalled = func(key string, stack []string) error {
+		installed, ok := registry.Plugins[key]
+		if !ok {
+			return nil
+		}
+		for _, dependency := range sortedKeys(installed.Dependencies) {
+			path := append(stack[:len(stack):len(stack)], dependency)
+			if state[dependency] == visiting {
+				return fmt.Errorf("Dependency cycle detected: %s", strings.Join(path, " -> "))
+			}
+			// cycles between installed plugins only already exist in the registry
+			if state[dependency] == visited || slices.Contains(stack, dependency) {
+				continue
+			}
+			if err := checkInstalled(dependency, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var visit func(source *PluginSource, stack []string) error
+	visit = func(source *PluginSource, stack []string) error {
+		key := pluginKey(source.Manifest)
+		state[key] = visiting
+		chosen[key] = source
+		dependencies := getDependencies(source.Manifest)
+		for _, dependency := range sortedKeys(dependencies) {
+			constraint := dependencies[dependency]
+			switch state[dependency] {
+			case visiting:
+				return fmt.Errorf(
+					"Dependency cycle detected: %s",
+					strings.Join(append(stack[:len(stack):len(stack)], dependency), " -> "),
+				)
+			case visited:
+				version, _ := chosen[dependency].Manifest["version"].(string)
+				ok, err := satisfiesConstraint(version, constraint)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf(
+						"Conflicting requirements for %s: %s is selected, but %s requires %s",
+						dependency, version, key, constraint,
+					)
+				}
+				continue
+			}
+			if installed, ok := registry.Plugins[dependency]; ok {
+				ok, err := satisfiesConstraint(installed.Version, constraint)
+				if err != nil {
+					return err
+				}
+				if ok {
+					if err := checkInstalled(dependency, append(stack[:len(stack):len(stack)], dependency)); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			candidate := pickPluginSource(available[dependency], constraint)
+			if candidate == nil {
+				return fmt.Errorf(
+					"Dependency %s %s of %s not found, provide it via --deps",
+					dependency, constraint, key,
+				)
+			}
+			if err := visit(candidate, append(stack[:len(stack):len(stack)], dependency)); err != nil {
+				return err
+			}
+		}
+		state[key] = visited
+		order = append(order, source)
+		return nil
+	}
+	if err := visit(root, []string{pluginKey(root.Manifest)}); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// checkDependentConstraints refuses to replace installed plugins with versions not accepted by other
+// installed plugins depending on them
+func checkDependentConstraints(order []*PluginSource, registry *PluginRegistry, force bool) {
+	replaced := map[string]bool{}
+	for _, source := range order {
+		replaced[pluginKey(source.Manifest)] = true
+	}
+	for _, source := range order {
+		key := pluginKey(source.Manifest)
+		version, _ := source.Manifest["version"].(string)
+		for _, dependent := range registry.Dependents(key) {
+			// constraints of plugins being replaced were already checked against their new manifests
+			if replaced[dependent] {
+				continue
+			}
+			constraint := registry.Plugins[dependent].Dependencies[key]
+			ok, err := satisfiesConstraint(version, constraint)
+			checkErr(err)
+			if ok {
+				continue
+			}
+			message := fmt.Sprintf("%s requires %s %s, but %s would be installed", dependent, key, constraint, version)
+			failUnlessForced(message, "use --force to ignore", force)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/urfave/cli/v3"
+)
+
+// changes are batched for this long before running reload commands
+const devReloadDelay = 300 * time.Millisecond
+
+var devIgnoredDirs = []string{".git", "__pycache__", "node_modules", ".pytest_cache", ".mypy_cache", ".ruff_cache"}
+
+func isDevIgnored(relPath string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(relPath), "/") {
+		if slices.Contains(devIgnoredDirs, part) || strings.HasSuffix(part, ".swp") || strings.HasSuffix(part, "~") {
+			return true
+		}
+	}
+	return false
+}
+
+func parseReloadCommands(values []string) map[string]string {
+	commands := map[string]string{}
+	for _, value := range values {
+		installType, command, ok := strings.Cut(value, "=")
+		if !ok || !slices.Contains(componentTypes, installType) {
+			exitErr(fmt.Sprintf("Error: invalid reload command %q, expected <type>=<command>", value))
+		}
+		commands[installType] = command
+	}
+	return commands
+}
+
+func watchRecursive(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if relPath, _ := filepath.Rel(root, path); relPath != "." && isDevIgnored(relPath) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// syncDevComponent replaces the installed component with a fresh copy of its source
+func syncDevComponent(component PluginComponent) {
+	if component.Type == "backend" {
+		createInitPyFile(filepath.Dir(component.Final))
+	}
+	checkErr(os.RemoveAll(component.Final))
+	createIfNotExists(filepath.Dir(component.Final), os.ModePerm)
+	checkErr(copyDirectory(component.Source, component.Final))
+}
+
+// mirrorChange applies a single filesystem event to the installed copy of the component
+func mirrorChange(watcher *fsnotify.Watcher, component PluginComponent, event fsnotify.Event) (bool, error) {
+	relPath, err := filepath.Rel(component.Source, event.Name)
+	if err != nil || relPath == "." || isDevIgnored(relPath) || event.Op == fsnotify.Chmod {
+		return false, nil
+	}
+	dest := filepath.Join(component.Final, relPath)
+	info, err := os.Lstat(event.Name)
+	if err != nil {
+		// removed or renamed away, renames also produce a create event for the new name
+		return true, os.RemoveAll(dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return false, err
+	}
+	switch {
+	case info.IsDir():
+		if err := watchRecursive(watcher, event.Name); err != nil {
+			return false, err
+		}
+		return true, copyDirectory(event.Name, dest)
+	case info.Mode()&os.ModeSymlink != 0:
+		if err := os.RemoveAll(dest); err != nil {
+			return false, err
+		}
+		return true, copySymlink(event.Name, dest)
+	}
+	return true, copyFile(event.Name, dest)
+}
+
+func runReloadCommand(installType string, command string, dir string) {
+	fmt.Printf("Reloading %s: %s\n", installType, command)
+	reloadCmd := exec.Command("sh", "-c", command)
+	reloadCmd.Dir = dir
+	reloadCmd.Stdout = os.Stdout
+	reloadCmd.Stderr = os.Stderr
+	if err := reloadCmd.Run(); err != nil {
+		fmt.Printf("Warning: reload command for %s failed: %s\n", installType, err)
+	}
+}
+
+func devPlugin(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path, err := filepath.Abs(args.Get(0))
+	checkErr(err)
+	manifest := readManifest(path).(map[string]interface{})
+	if !hasValidInstalls(manifest) {
+		exitErr("Error: manifest does not have valid installs")
+	}
+	reloadCommands := parseReloadCommands(cmd.StringSlice("reload"))
+	components := resolvePluginComponents(path, manifest)
+	if cmd.Bool("save") {
+		rootOptions.WriteToDisk()
+	}
+	watcher, err := fsnotify.NewWatcher()
+	checkErr(err)
+	defer watcher.Close()
+	for _, component := range components {
+		syncDevComponent(component)
+		checkErr(watchRecursive(watcher, component.Source))
+		fmt.Printf("Synced %s component %s to %s\n", component.Type, component.Name, component.Final)
+	}
+	fmt.Println("Watching for changes, press Ctrl+C to stop")
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	changed := map[string]bool{}
+	timer := time.NewTimer(devReloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			for _, component := range components {
+				if event.Name != component.Source && !strings.HasPrefix(event.Name, component.Source+string(filepath.Separator)) {
+					continue
+				}
+				mirrored, err := mirrorChange(watcher, component, event)
+				if err != nil {
+					fmt.Printf("Warning: failed to sync %s: %s\n", event.Name, err)
+				}
+				if mirrored {
+					fmt.Printf("%s %s\n", strings.ToLower(event.Op.String()), event.Name)
+					changed[component.Type] = true
+					timer.Reset(devReloadDelay)
+				}
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Println("Warning: watcher error:", err)
+		case <-timer.C:
+			for _, installType := range componentTypes {
+				if command, ok := reloadCommands[installType]; ok && changed[installType] {
+					runReloadCommand(installType, command, *getComponentConfigEntry(installType))
+				}
+			}
+			changed = map[string]bool{}
+		case <-signals:
+			fmt.Println("Stopped watching")
+			return nil
+		}
+	}
+}
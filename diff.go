@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	diffContext = 3
+	// files with more line pairs than this are reported as modified without a diff
+	maxDiffSize = 4_000_000
+)
+
+type diffOp struct {
+	Kind byte // ' ', '-' or '+'
+	Line string
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.Split(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func isTextFile(data []byte) bool {
+	return utf8.Valid(data) && !bytes.Contains(data, []byte{0})
+}
+
+// diffLines computes line operations turning a into b using the longest common subsequence
+func diffLines(a []string, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+func hunkRange(start int, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// unifiedDiff returns a unified diff of two texts, or an empty string if they are equal
+func unifiedDiff(a []byte, b []byte, nameA string, nameB string) string {
+	ops := diffLines(splitLines(a), splitLines(b))
+	var out strings.Builder
+	// positions of the op in a and b
+	posA, posB := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for k, op := range ops {
+		posA[k+1], posB[k+1] = posA[k], posB[k]
+		if op.Kind != '+' {
+			posA[k+1]++
+		}
+		if op.Kind != '-' {
+			posB[k+1]++
+		}
+	}
+	for k := 0; k < len(ops); {
+		if ops[k].Kind == ' ' {
+			k++
+			continue
+		}
+		start := max(k-diffContext, 0)
+		end := k
+		// extend the hunk while changes are closer than two contexts apart
+		for unchanged := 0; end < len(ops) && unchanged <= 2*diffContext; end++ {
+			if ops[end].Kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		for end > k && ops[end-1].Kind == ' ' {
+			end--
+		}
+		end = min(end+diffContext, len(ops))
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+		fmt.Fprintf(
+			&out,
+			"@@ -%s +%s @@\n",
+			hunkRange(posA[start], posA[end]-posA[start]),
+			hunkRange(posB[start], posB[end]-posB[start]),
+		)
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.Kind)
+			out.WriteString(op.Line)
+			out.WriteByte('\n')
+		}
+		k = end
+	}
+	return out.String()
+}
+
+func diffFile(installedPath string, sourcePath string, relPath string) string {
+	var installedData, sourceData []byte
+	var err error
+	if installedPath != "" {
+		installedData, err = os.ReadFile(installedPath)
+		checkErr(err)
+	}
+	if sourcePath != "" {
+		sourceData, err = os.ReadFile(sourcePath)
+		checkErr(err)
+	}
+	nameA, nameB := "installed/"+relPath, "source/"+relPath
+	if installedPath == "" {
+		nameA = "/dev/null"
+	}
+	if sourcePath == "" {
+		nameB = "/dev/null"
+	}
+	if !isTextFile(installedData) || !isTextFile(sourceData) {
+		return fmt.Sprintf("Binary files %s and %s differ\n", nameA, nameB)
+	}
+	if len(splitLines(installedData))*len(splitLines(sourceData)) > maxDiffSize {
+		return fmt.Sprintf("Files %s and %s differ (too large to diff)\n", nameA, nameB)
+	}
+	return unifiedDiff(installedData, sourceData, nameA, nameB)
+}
+
+func diffPlugin(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	source, cleanup := openPluginSource(args.Get(0))
+	defer cleanup()
+	if !hasValidInstalls(source.Manifest) {
+		exitErr("Error: manifest does not have valid installs")
+	}
+	nameOnly := cmd.Bool("name-only")
+	differs := false
+	for _, component := range resolvePluginComponents(source.Path, source.Manifest) {
+		fmt.Printf("%s component %s (%s)\n", component.Type, component.Name, component.Final)
+		if target, err := os.Readlink(component.Final); err == nil && filepath.Clean(target) == component.Source {
+			fmt.Println("  linked to source (dev install)")
+			continue
+		}
+		if !isDir(component.Final) {
+			fmt.Println("  not installed")
+			differs = true
+			continue
+		}
+		sourceHashes, err := hashTree(component.Source)
+		checkErr(err)
+		installedHashes, err := hashTree(component.Final)
+		checkErr(err)
+		changed := compareTrees(sourceHashes, installedHashes)
+		if len(changed) == 0 {
+			fmt.Println("  up to date")
+			continue
+		}
+		differs = true
+		var diffs strings.Builder
+		for _, relPath := range changed {
+			sourcePath := filepath.Join(component.Source, filepath.FromSlash(relPath))
+			installedPath := filepath.Join(component.Final, filepath.FromSlash(relPath))
+			_, inSource := sourceHashes[relPath]
+			_, inInstalled := installedHashes[relPath]
+			switch {
+			case !inInstalled:
+				fmt.Printf("  added: %s\n", relPath)
+				installedPath = ""
+			case !inSource:
+				fmt.Printf("  removed: %s\n", relPath)
+				sourcePath = ""
+			default:
+				fmt.Printf("  modified: %s\n", relPath)
+			}
+			if !nameOnly {
+				diffs.WriteString(diffFile(installedPath, sourcePath, relPath))
+			}
+		}
+		if diffs.Len() > 0 {
+			fmt.Printf("\n%s\n", strings.TrimRight(diffs.String(), "\n"))
+		}
+	}
+	if differs && cmd.Bool("exit-code") {
+		os.Exit(1)
+	}
+	return nil
+}
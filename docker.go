@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// core bitcart-docker services, which plugins are allowed to extend without namespacing
+var coreDockerServices = []string{
+	"admin", "backend", "bitcart", "database", "nginx", "nginx-gen", "letsencrypt-nginx-proxy-companion",
+	"redis", "store", "tor", "tor-gen", "worker",
+}
+
+var envReferenceRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)([^}]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+func isNamespacedService(service string, author string, name string) bool {
+	for _, prefix := range []string{name, author} {
+		if service == prefix || strings.HasPrefix(service, prefix+"-") || strings.HasPrefix(service, prefix+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+func getComposeFragments(path string) []string {
+	var fragments []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, err := filepath.Glob(filepath.Join(path, pattern))
+		checkErr(err)
+		fragments = append(fragments, matches...)
+	}
+	sort.Strings(fragments)
+	return fragments
+}
+
+// collectEnvReferences finds ${VAR} and $VAR references without defaults in all string values
+func collectEnvReferences(value interface{}, refs map[string]bool) {
+	switch value := value.(type) {
+	case string:
+		value = strings.ReplaceAll(value, "$$", "")
+		for _, match := range envReferenceRegex.FindAllStringSubmatch(value, -1) {
+			if match[1] != "" {
+				if !strings.HasPrefix(match[2], ":-") && !strings.HasPrefix(match[2], "-") {
+					refs[match[1]] = true
+				}
+			} else {
+				refs[match[3]] = true
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range value {
+			collectEnvReferences(item, refs)
+		}
+	case []interface{}:
+		for _, item := range value {
+			collectEnvReferences(item, refs)
+		}
+	}
+}
+
+func toStringList(value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var result []string
+		for _, item := range value {
+			switch item := item.(type) {
+			case string:
+				result = append(result, item)
+			case map[string]interface{}:
+				if path, ok := item["path"].(string); ok {
+					result = append(result, path)
+				}
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func isRelativeHostPath(path string) bool {
+	return !filepath.IsAbs(path) && !strings.HasPrefix(path, "~") && !strings.Contains(path, "$")
+}
+
+// getReferencedFiles returns relative host paths used by a service: env files, build contexts and bind mounts
+func getReferencedFiles(service map[string]interface{}) []string {
+	var files []string
+	candidates := toStringList(service["env_file"])
+	switch build := service["build"].(type) {
+	case string:
+		candidates = append(candidates, build)
+	case map[string]interface{}:
+		if context, ok := build["context"].(string); ok {
+			candidates = append(candidates, context)
+		}
+	}
+	for _, file := range candidates {
+		if isRelativeHostPath(file) && !strings.Contains(file, "://") {
+			files = append(files, file)
+		}
+	}
+	var volumes []string
+	for _, volume := range toStringList(service["volumes"]) {
+		volumes = append(volumes, strings.SplitN(volume, ":", 2)[0])
+	}
+	if items, ok := service["volumes"].([]interface{}); ok {
+		for _, volume := range items {
+			if volume, ok := volume.(map[string]interface{}); ok && volume["type"] == "bind" {
+				if source, ok := volume["source"].(string); ok {
+					volumes = append(volumes, source)
+				}
+			}
+		}
+	}
+	for _, volume := range volumes {
+		if volume == "." || strings.HasPrefix(volume, "./") || strings.HasPrefix(volume, "../") {
+			files = append(files, volume)
+		}
+	}
+	return files
+}
+
+func validateDocker(report *ValidationReport, root string, path string, location string, author string, name string) {
+	fragments := getComposeFragments(path)
+	if len(fragments) == 0 {
+		report.Error(
+			"docker-fragment",
+			relativeToPlugin(root, path),
+			location,
+			"Docker component does not include any compose fragment",
+			"Add a docker-compose fragment, i.e. plugin.yml with services to add or extend",
+		)
+		return
+	}
+	for _, fragment := range fragments {
+		file := relativeToPlugin(root, fragment)
+		data, err := os.ReadFile(fragment)
+		checkErr(err)
+		var compose map[string]interface{}
+		if err := yaml.Unmarshal(data, &compose); err != nil {
+			report.Error("docker-yaml", file, "", fmt.Sprintf("Invalid YAML: %s", err), "Fix YAML syntax")
+			continue
+		}
+		for _, key := range sortedKeys(compose) {
+			if !strings.HasPrefix(key, "x-") && key != "services" && key != "volumes" && key != "networks" {
+				report.Warning(
+					"docker-key",
+					file,
+					"/"+key,
+					fmt.Sprintf("Unexpected top-level key %s in compose fragment", key),
+					"Only services, volumes, networks and x- extension keys are merged",
+				)
+			}
+		}
+		services, ok := compose["services"].(map[string]interface{})
+		if compose["services"] != nil && !ok {
+			report.Error("docker-yaml", file, "/services", "services must be a mapping", "Define services as a mapping of names")
+			continue
+		}
+		for _, serviceName := range sortedKeys(services) {
+			serviceLocation := "/services/" + serviceName
+			if !isNamespacedService(serviceName, author, name) && !slices.Contains(coreDockerServices, serviceName) {
+				report.Error(
+					"docker-namespace",
+					file,
+					serviceLocation,
+					fmt.Sprintf("Service %s is not namespaced and may collide with other plugins", serviceName),
+					fmt.Sprintf("Rename it to %s-%s", name, serviceName),
+				)
+			}
+			service, ok := services[serviceName].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			definedEnv := map[string]bool{}
+			for _, reference := range getReferencedFiles(service) {
+				referencePath := filepath.Join(filepath.Dir(fragment), reference)
+				if !exists(referencePath) {
+					report.Error(
+						"docker-file",
+						file,
+						serviceLocation,
+						fmt.Sprintf("Referenced path %s does not exist", reference),
+						"Add the file to the docker component or fix the path",
+					)
+				}
+			}
+			for _, envFile := range toStringList(service["env_file"]) {
+				if values, err := godotenv.Read(filepath.Join(filepath.Dir(fragment), envFile)); err == nil {
+					for key := range values {
+						definedEnv[key] = true
+					}
+				}
+			}
+			refs := map[string]bool{}
+			collectEnvReferences(service, refs)
+			for _, ref := range sortedKeys(refs) {
+				if !definedEnv[ref] {
+					report.Warning(
+						"docker-env",
+						file,
+						serviceLocation,
+						fmt.Sprintf("Environment variable %s is referenced without a default", ref),
+						fmt.Sprintf("Use ${%s:-default} or define it in an env_file", ref),
+					)
+				}
+			}
+		}
+	}
+}
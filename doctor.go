@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// hashTree returns sha256 checksums of files in the directory, ignoring caches and other generated files
+func hashTree(root string) (map[string]string, error) {
+	hashes := map[string]string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if relPath != "." && isDevIgnored(relPath) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		var data []byte
+		switch {
+		case d.IsDir():
+			return nil
+		case d.Type()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			data = []byte(target)
+		default:
+			if data, err = os.ReadFile(path); err != nil {
+				return err
+			}
+		}
+		hash := sha256.Sum256(data)
+		hashes[filepath.ToSlash(relPath)] = hex.EncodeToString(hash[:])
+		return nil
+	})
+	return hashes, err
+}
+
+// compareTrees returns paths which differ between source and installed copies
+func compareTrees(source map[string]string, installed map[string]string) []string {
+	var changed []string
+	for _, path := range sortedKeys(source) {
+		if installed[path] != source[path] {
+			changed = append(changed, path)
+		}
+	}
+	for _, path := range sortedKeys(installed) {
+		if _, ok := source[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// getModuleDirectories returns organization directories containing plugins in the repository
+func getModuleDirectories(repoPath string, componentType string) []string {
+	var dirs []string
+	if componentType == "docker" {
+		return []string{filepath.Join(repoPath, "compose/plugins/docker")}
+	}
+	entries, err := os.ReadDir(filepath.Join(repoPath, "modules"))
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "@") == (componentType != "backend") {
+			dirs = append(dirs, filepath.Join(repoPath, "modules", entry.Name()))
+		}
+	}
+	return dirs
+}
+
+func diagnoseRepository(repoPath string, componentType string) []LintIssue {
+	var issues []LintIssue
+	for _, dir := range getModuleDirectories(repoPath, componentType) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		hasPlugins := false
+		for _, entry := range entries {
+			entryPath := filepath.Join(dir, entry.Name())
+			if entry.Name() == "__init__.py" || entry.Name() == "__pycache__" {
+				continue
+			}
+			if entry.Type()&os.ModeSymlink != 0 {
+				if _, err := os.Stat(entryPath); err != nil {
+					target, _ := os.Readlink(entryPath)
+					issues = append(issues, LintIssue{
+						Rule:       "dangling-symlink",
+						File:       entryPath,
+						Message:    fmt.Sprintf("Symlink points to missing %s", target),
+						Suggestion: "Remove the symlink or reinstall the plugin from its new location",
+						Fix: func() {
+							checkErr(os.Remove(entryPath))
+							if componentType == "backend" {
+								removeOrgInitIfNoPlugins(dir)
+							}
+						},
+					})
+					continue
+				}
+			}
+			if entry.IsDir() || entry.Type()&os.ModeSymlink != 0 {
+				hasPlugins = true
+			}
+		}
+		if componentType != "backend" {
+			continue
+		}
+		initPy := filepath.Join(dir, "__init__.py")
+		switch {
+		case !hasPlugins && exists(initPy):
+			issues = append(issues, LintIssue{
+				Rule:       "orphan-init",
+				File:       initPy,
+				Message:    "Organization directory does not contain any plugins",
+				Suggestion: "Remove the orphaned __init__.py",
+				Fix:        func() { removeOrgInitIfNoPlugins(dir) },
+			})
+		case hasPlugins && !exists(initPy):
+			issues = append(issues, LintIssue{
+				Rule:       "missing-init",
+				File:       initPy,
+				Message:    "Organization directory is missing __init__.py",
+				Suggestion: "Add an empty __init__.py",
+				Fix:        func() { createInitPyFile(dir) },
+			})
+		}
+	}
+	return issues
+}
+
+// diagnoseInstalledPlugin checks registry components against the plugin source they were installed from
+func diagnoseInstalledPlugin(key string, plugin *InstalledPlugin) []LintIssue {
+	if !exists(plugin.Source) {
+		return []LintIssue{{
+			Rule:       "missing-source",
+			File:       plugin.Source,
+			Message:    fmt.Sprintf("Source of %s no longer exists, unable to check installed files", key),
+			Suggestion: "Reinstall the plugin from its new location",
+			Severity:   "warning",
+		}}
+	}
+	source, cleanup := openPluginSource(plugin.Source)
+	defer cleanup()
+	sourcePaths := map[string]string{}
+	iterateInstallations(source.Path, source.Manifest, func(componentPath, componentName, installType string) {
+		sourcePaths[installType+"/"+componentName] = componentPath
+	})
+	var issues []LintIssue
+	for _, component := range plugin.Components {
+		installedPath := component.Path
+		sourcePath, ok := sourcePaths[component.Type+"/"+component.Name]
+		if !ok {
+			issues = append(issues, LintIssue{
+				Rule:       "removed-component",
+				File:       installedPath,
+				Message:    fmt.Sprintf("Component %s was removed from %s source", component.Name, key),
+				Suggestion: "Upgrade the plugin to remove stale components",
+				Severity:   "warning",
+			})
+			continue
+		}
+		if plugin.Dev {
+			if target, err := os.Readlink(installedPath); err != nil || filepath.Clean(target) != sourcePath {
+				issues = append(issues, LintIssue{
+					Rule:       "broken-dev-install",
+					File:       installedPath,
+					Message:    fmt.Sprintf("Component of %s is not linked to %s", key, sourcePath),
+					Suggestion: "Recreate the symlink",
+					Fix:        func() { safeSymlink(sourcePath, installedPath) },
+				})
+			}
+			continue
+		}
+		var reinstall func()
+		// archives are extracted into a temporary directory removed before fixes are applied
+		if source.Path == source.Origin {
+			reinstall = func() {
+				checkErr(os.RemoveAll(installedPath))
+				checkErr(copyDirectory(sourcePath, installedPath))
+			}
+		}
+		if !isDir(installedPath) {
+			issues = append(issues, LintIssue{
+				Rule:       "missing-install",
+				File:       installedPath,
+				Message:    fmt.Sprintf("Component of %s is missing", key),
+				Suggestion: "Reinstall the component",
+				Fix:        reinstall,
+			})
+			continue
+		}
+		sourceHashes, err := hashTree(sourcePath)
+		checkErr(err)
+		installedHashes, err := hashTree(installedPath)
+		checkErr(err)
+		if changed := compareTrees(sourceHashes, installedHashes); len(changed) > 0 {
+			issues = append(issues, LintIssue{
+				Rule:       "outdated-install",
+				File:       installedPath,
+				Message:    fmt.Sprintf("Component of %s differs from source in %s", key, strings.Join(changed, ", ")),
+				Suggestion: "Reinstall the plugin to update installed files",
+				Fix:        reinstall,
+			})
+		}
+	}
+	return issues
+}
+
+func doctorPlugin(ctx context.Context, cmd *cli.Command) error {
+	var issues []LintIssue
+	for _, componentType := range componentTypes {
+		repoPath := *getComponentConfigEntry(componentType)
+		if repoPath == "" {
+			continue
+		}
+		issues = append(issues, diagnoseRepository(repoPath, componentType)...)
+	}
+	registry := &PluginRegistry{}
+	registry.Load()
+	for _, key := range sortedKeys(registry.Plugins) {
+		issues = append(issues, diagnoseInstalledPlugin(key, registry.Plugins[key])...)
+	}
+	report := &ValidationReport{Path: SettingsPath(), SuccessMessage: "No problems found"}
+	report.addLintIssues(issues, cmd.Bool("fix"))
+	report.Print(cmd.String("format"))
+	return nil
+}
@@ -2,43 +2,67 @@ package main
 
 import (
 	"archive/zip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
 )
 
-func copyDirectory(scrDir, dest string) {
-	createIfNotExists(dest, 0755)
+func copyDirectory(scrDir, dest string) error {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
 	entries, err := os.ReadDir(scrDir)
-	checkErr(err)
+	if err != nil {
+		return err
+	}
 	for _, entry := range entries {
 		sourcePath := filepath.Join(scrDir, entry.Name())
 		destPath := filepath.Join(dest, entry.Name())
 		fileInfo, err := os.Stat(sourcePath)
-		checkErr(err)
+		if err != nil {
+			return err
+		}
 		switch fileInfo.Mode() & os.ModeType {
 		case os.ModeDir:
-			createIfNotExists(destPath, 0755)
-			copyDirectory(sourcePath, destPath)
+			err = copyDirectory(sourcePath, destPath)
 		case os.ModeSymlink:
-			copySymlink(sourcePath, destPath)
+			err = copySymlink(sourcePath, destPath)
 		default:
-			copyFile(sourcePath, destPath)
+			err = copyFile(sourcePath, destPath)
+		}
+		if err != nil {
+			return err
 		}
 		fInfo, err := entry.Info()
-		checkErr(err)
+		if err != nil {
+			return err
+		}
 		isSymlink := fInfo.Mode()&os.ModeSymlink != 0
 		if !isSymlink {
-			checkErr(os.Chmod(destPath, fInfo.Mode()))
+			if err := os.Chmod(destPath, fInfo.Mode()); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
-func copyFile(src, dest string) {
+func copyFile(src, dest string) error {
 	data, err := os.ReadFile(src)
-	checkErr(err)
-	copyData(data, dest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, os.ModePerm)
 }
 
 func copyData(src []byte, dst string) {
@@ -58,10 +82,12 @@ func createIfNotExists(dir string, perm os.FileMode) {
 	}
 }
 
-func copySymlink(source, dest string) {
+func copySymlink(source, dest string) error {
 	link, err := os.Readlink(source)
-	checkErr(err)
-	checkErr(os.Symlink(link, dest))
+	if err != nil {
+		return err
+	}
+	return os.Symlink(link, dest)
 }
 
 func safeSymlink(src, dst string) {
@@ -70,37 +96,146 @@ func safeSymlink(src, dst string) {
 	checkErr(os.Symlink(src, dst))
 }
 
-func createZip(in string, out string) {
-	file, err := os.Create(out)
+// collectPackageFiles returns sorted slash-separated paths of files to package and the excluded paths.
+// The output file and other packages in the plugin root are always skipped
+func collectPackageFiles(in string, out string, exclude RejectByNameFunc) ([]string, []string) {
+	var included, excluded []string
+	in, err := filepath.Abs(in)
+	checkErr(err)
+	out, err = filepath.Abs(out)
 	checkErr(err)
-	defer file.Close()
-	w := zip.NewWriter(file)
-	defer w.Close()
 	walker := func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 		relPath, err := filepath.Rel(in, path)
 		checkErr(err)
-		if relPath == filepath.Base(out) {
+		if relPath == "." || path == out ||
+			(!info.IsDir() && filepath.Dir(relPath) == "." && strings.HasSuffix(relPath, ".bitcart")) {
 			return nil
 		}
-		if relPath == ".git" {
+		if relPath == ".git" || relPath == signatureDir {
 			return filepath.SkipDir
 		}
-		fmt.Printf("Crawling: %#v\n", relPath)
-		if info.IsDir() {
+		if exclude(path) {
+			excluded = append(excluded, filepath.ToSlash(relPath))
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
 			return nil
 		}
-		file, err := os.Open(path)
+		if !info.IsDir() {
+			included = append(included, filepath.ToSlash(relPath))
+		}
+		return nil
+	}
+	checkErr(filepath.Walk(in, walker))
+	sort.Strings(included)
+	sort.Strings(excluded)
+	return included, excluded
+}
+
+// getPackageTimestamp returns the time used for all archive entries: SOURCE_DATE_EPOCH if set,
+// otherwise the time of the last git commit, falling back to the zip epoch
+func getPackageTimestamp(path string) time.Time {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
 		checkErr(err)
-		defer file.Close()
+		return time.Unix(seconds, 0).UTC()
+	}
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err == nil {
+		if head, err := repo.Head(); err == nil {
+			if commit, err := repo.CommitObject(head.Hash()); err == nil {
+				return commit.Committer.When.UTC()
+			}
+		}
+	}
+	return time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// createZip packages files of the in directory, and signs the archive if key is provided
+func createZip(in string, out string, exclude RejectByNameFunc, key ed25519.PrivateKey) {
+	files, _ := collectPackageFiles(in, out, exclude)
+	modified := getPackageTimestamp(in)
+	file, err := os.Create(out)
+	checkErr(err)
+	defer file.Close()
+	w := zip.NewWriter(file)
+	defer w.Close()
+	checksums := map[string]string{}
+	for _, relPath := range files {
+		fmt.Printf("Crawling: %#v\n", relPath)
+		path := filepath.Join(in, filepath.FromSlash(relPath))
+		info, err := os.Stat(path)
 		checkErr(err)
-		f, err := w.Create(relPath)
+		mode := os.FileMode(0644)
+		if info.Mode()&0111 != 0 {
+			mode = 0755
+		}
+		header := &zip.FileHeader{Name: relPath, Method: zip.Deflate, Modified: modified}
+		header.SetMode(mode)
+		f, err := w.CreateHeader(header)
 		checkErr(err)
-		_, err = io.Copy(f, file)
+		src, err := os.Open(path)
 		checkErr(err)
-		return nil
+		hash := sha256.New()
+		_, err = io.Copy(io.MultiWriter(f, hash), src)
+		checkErr(err)
+		checkErr(src.Close())
+		checksums[relPath] = hex.EncodeToString(hash.Sum(nil))
+	}
+	if key == nil {
+		return
+	}
+	signatureFiles := buildSignatureFiles(checksums, key)
+	for _, name := range sortedKeys(signatureFiles) {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified}
+		header.SetMode(0644)
+		f, err := w.CreateHeader(header)
+		checkErr(err)
+		_, err = f.Write(signatureFiles[name])
+		checkErr(err)
+	}
+}
+
+func safeJoin(base, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("Illegal absolute path in archive: %s", name)
+	}
+	target := filepath.Join(base, filepath.FromSlash(name))
+	relPath, err := filepath.Rel(base, target)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Illegal path in archive: %s", name)
+	}
+	return target, nil
+}
+
+func extractZip(in string, out string) {
+	r, err := zip.OpenReader(in)
+	checkErr(err)
+	defer r.Close()
+	// validate all entries before extracting, so that a malicious archive doesn't leave partial output behind
+	targets := make([]string, len(r.File))
+	for i, f := range r.File {
+		targets[i], err = safeJoin(out, f.Name)
+		checkErr(err)
+	}
+	createIfNotExists(out, 0755)
+	for i, f := range r.File {
+		target := targets[i]
+		if f.FileInfo().IsDir() {
+			createIfNotExists(target, 0755)
+			continue
+		}
+		createIfNotExists(filepath.Dir(target), 0755)
+		src, err := f.Open()
+		checkErr(err)
+		dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode().Perm()|0600)
+		checkErr(err)
+		_, err = io.Copy(dst, src)
+		checkErr(err)
+		checkErr(dst.Close())
+		checkErr(src.Close())
 	}
-	checkErr(filepath.Walk(in, walker))
 }
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/urfave/cli/v3"
+)
+
+var (
+	exportDefaultRegex = regexp.MustCompile(`(?m)^export default\s*`)
+	identifierRegex    = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	jsIdentifierRegex  = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+func pascalCase(name string) string {
+	var result strings.Builder
+	for _, part := range identifierRegex.Split(name, -1) {
+		if part == "" {
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		result.WriteString(string(runes))
+	}
+	return result.String()
+}
+
+// scanJS returns the index of the bracket closing the one at openIndex, skipping strings and comments
+func scanJS(text string, openIndex int) int {
+	depth := 0
+	for i := openIndex; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '"', '\'', '`':
+			for i++; i < len(text) && text[i] != c; i++ {
+				if text[i] == '\\' {
+					i++
+				}
+			}
+		case '/':
+			if strings.HasPrefix(text[i:], "//") {
+				for i < len(text) && text[i] != '\n' {
+					i++
+				}
+			} else if strings.HasPrefix(text[i:], "/*") {
+				end := strings.Index(text[i+2:], "*/")
+				if end == -1 {
+					return -1
+				}
+				i += end + 3
+			}
+		case '{', '[', '(':
+			depth++
+		case '}', ']', ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func lineIndent(text string, index int) string {
+	lineStart := strings.LastIndex(text[:index], "\n") + 1
+	line := text[lineStart:]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// insertIntoLiteral appends an item to the array or object literal opening at openIndex
+func insertIntoLiteral(text string, openIndex int, item string) string {
+	closeIndex := scanJS(text, openIndex)
+	if closeIndex == -1 {
+		exitErr("Error: unbalanced brackets, unable to edit the file")
+	}
+	inner := text[openIndex+1 : closeIndex]
+	if !strings.Contains(inner, "\n") && !strings.Contains(item, "\n") && strings.TrimSpace(inner) != "" {
+		return text[:closeIndex] + ", " + item + text[closeIndex:]
+	}
+	indent := lineIndent(text, openIndex)
+	lines := strings.Split(item, "\n")
+	for i, line := range lines {
+		lines[i] = indent + "  " + line
+	}
+	trimmed := strings.TrimRight(inner, " \t\n")
+	if strings.TrimSpace(trimmed) != "" && !strings.HasSuffix(trimmed, ",") {
+		trimmed += ","
+	}
+	return text[:openIndex+1] + trimmed + "\n" + strings.Join(lines, "\n") + ",\n" + indent + text[closeIndex:]
+}
+
+// findKey returns the index of the value of a top-level key of the object literal opening at openIndex
+func findKey(text string, openIndex int, key string) int {
+	closeIndex := scanJS(text, openIndex)
+	keyRegex := regexp.MustCompile(`^["']?` + regexp.QuoteMeta(key) + `["']?\s*:\s*`)
+	expectKey := true
+	for i := openIndex + 1; i < closeIndex; i++ {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		case strings.HasPrefix(text[i:], "//") || strings.HasPrefix(text[i:], "/*"):
+			i = skipToken(text, i)
+			continue
+		}
+		if expectKey {
+			if loc := keyRegex.FindStringIndex(text[i:closeIndex]); loc != nil {
+				return i + loc[1]
+			}
+			expectKey = false
+		}
+		switch c {
+		case '{', '[', '(':
+			i = scanJS(text, i)
+		case '"', '\'', '`':
+			i = skipToken(text, i)
+		case ',':
+			expectKey = true
+		}
+	}
+	return -1
+}
+
+// skipToken returns the index of the last character of the string or comment starting at index
+func skipToken(text string, index int) int {
+	switch c := text[index]; c {
+	case '"', '\'', '`':
+		i := index + 1
+		for ; i < len(text) && text[i] != c; i++ {
+			if text[i] == '\\' {
+				i++
+			}
+		}
+		return i
+	}
+	if strings.HasPrefix(text[index:], "//") {
+		end := strings.Index(text[index:], "\n")
+		if end == -1 {
+			return len(text)
+		}
+		return index + end
+	}
+	end := strings.Index(text[index+2:], "*/")
+	if end == -1 {
+		return len(text)
+	}
+	return index + end + 3
+}
+
+// addImport adds an import statement after existing imports, or before the default export
+func addImport(text string, statement string) string {
+	lines := strings.Split(text, "\n")
+	if strings.Contains(text, statement) {
+		return text
+	}
+	lastImport := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "import ") {
+			lastImport = i
+		}
+	}
+	if lastImport != -1 {
+		lines = append(lines[:lastImport+1], append([]string{statement}, lines[lastImport+1:]...)...)
+		return strings.Join(lines, "\n")
+	}
+	loc := exportDefaultRegex.FindStringIndex(text)
+	if loc == nil {
+		return statement + "\n\n" + text
+	}
+	return text[:loc[0]] + statement + "\n\n" + text[loc[0]:]
+}
+
+// getDefaultExport returns the index of the literal exported by default, adding the export if it's missing
+func getDefaultExport(text string, literal string) (string, int) {
+	loc := exportDefaultRegex.FindStringIndex(text)
+	if loc == nil {
+		text = strings.TrimRight(text, "\n") + "\n\nexport default " + literal + "\n"
+		loc = exportDefaultRegex.FindStringIndex(text)
+	}
+	if !strings.HasPrefix(text[loc[1]:], literal[:1]) {
+		exitErr(fmt.Sprintf("Error: default export is not a %s literal, unable to edit the file", literal))
+	}
+	return text, loc[1]
+}
+
+// findFrontendComponent looks up admin or store component by name or type/name
+func findFrontendComponent(path string, manifest map[string]interface{}, ref string) (string, string) {
+	var matches []string
+	var componentName string
+	if !hasValidInstalls(manifest) {
+		exitErr("Error: manifest does not have valid installs")
+	}
+	iterateInstallations(path, manifest, func(componentPath, name, installType string) {
+		if (installType == "admin" || installType == "store") && (ref == name || ref == installType+"/"+name) {
+			matches = append(matches, componentPath)
+			componentName = name
+		}
+	})
+	switch len(matches) {
+	case 0:
+		exitErr(fmt.Sprintf("Error: frontend component %s not found", ref))
+	case 1:
+		return matches[0], componentName
+	}
+	exitErr(fmt.Sprintf("Error: component name %s is ambiguous, use admin/%s or store/%s", ref, ref, ref))
+	return "", ""
+}
+
+func readConfigFile(componentPath string, name string) (string, string) {
+	filePath := filepath.Join(componentPath, "config", name)
+	data, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		checkErr(err)
+	}
+	return filePath, string(data)
+}
+
+// checkIdentifier ensures the generated component can be imported under this name
+func checkIdentifier(identifier string, source string) {
+	if !jsIdentifierRegex.MatchString(identifier) {
+		exitErr(fmt.Sprintf("Error: %q does not produce a valid JavaScript identifier (got %q)", source, identifier))
+	}
+}
+
+func writeGeneratedFile(filePath string, templatePath string, data interface{}) {
+	if exists(filePath) {
+		exitErr(fmt.Sprintf("Error: %s already exists", filePath))
+	}
+	createIfNotExists(filepath.Dir(filePath), os.ModePerm)
+	writeTemplate(filePath, templatePath, data)
+}
+
+func generatePage(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 2 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path := cmd.String("path")
+	manifest := readManifest(path).(map[string]interface{})
+	componentPath, componentName := findFrontendComponent(path, manifest, args.Get(0))
+	name := args.Get(1)
+	if err := pageNameValidator(name); err != nil {
+		exitErr(fmt.Sprintf("Error: invalid page name %q: %s", name, err))
+	}
+	identifier := pascalCase(name) + "Page"
+	checkIdentifier(identifier, name)
+	writeGeneratedFile(
+		filepath.Join(componentPath, "pages", name+".vue"),
+		"plugin/src/frontend/pages/page.vue.tmpl",
+		map[string]string{"Title": name, "Identifier": identifier},
+	)
+	routesPath, routes := readConfigFile(componentPath, "routes.js")
+	routes = addImport(
+		routes,
+		fmt.Sprintf("import %s from \"@%s-%s/pages/%s\"", identifier, manifest["author"], componentName, name),
+	)
+	routes, index := getDefaultExport(routes, "[]")
+	routes = insertIntoLiteral(routes, index, fmt.Sprintf(
+		"{\n  name: %q,\n  path: %q,\n  component: %s,\n}",
+		componentName+"-"+name,
+		"/"+name,
+		identifier,
+	))
+	checkErr(os.WriteFile(routesPath, []byte(routes), os.ModePerm))
+	fmt.Printf("Page %s created and registered at /%s\n", name, name)
+	return nil
+}
+
+func generateSlot(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 3 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path := cmd.String("path")
+	manifest := readManifest(path).(map[string]interface{})
+	componentPath, componentName := findFrontendComponent(path, manifest, args.Get(0))
+	slot := args.Get(1)
+	identifier := pascalCase(args.Get(2))
+	checkIdentifier(identifier, args.Get(2))
+	writeGeneratedFile(
+		filepath.Join(componentPath, "components", identifier+".vue"),
+		"plugin/src/frontend/components/component.vue.tmpl",
+		map[string]string{"Slot": slot, "Identifier": identifier},
+	)
+	extendsPath, extends := readConfigFile(componentPath, "extends.js")
+	extends = addImport(
+		extends,
+		fmt.Sprintf("import %s from \"@%s-%s/components/%s\"", identifier, manifest["author"], componentName, identifier),
+	)
+	extends, index := getDefaultExport(extends, "{}")
+	slotKey := slot
+	if !jsIdentifierRegex.MatchString(slot) {
+		slotKey = fmt.Sprintf("%q", slot)
+	}
+	if componentsIndex := findKey(extends, index, "extendComponents"); componentsIndex == -1 {
+		extends = insertIntoLiteral(extends, index, fmt.Sprintf("extendComponents: {\n  %s: [%s],\n}", slotKey, identifier))
+	} else if slotIndex := findKey(extends, componentsIndex, slot); slotIndex == -1 {
+		extends = insertIntoLiteral(extends, componentsIndex, fmt.Sprintf("%s: [%s]", slotKey, identifier))
+	} else {
+		extends = insertIntoLiteral(extends, slotIndex, identifier)
+	}
+	checkErr(os.WriteFile(extendsPath, []byte(extends), os.ModePerm))
+	fmt.Printf("Component %s created and registered in %s slot\n", identifier, slot)
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+)
+
+type LintIssue struct {
+	Rule       string
+	File       string
+	Message    string
+	Suggestion string
+	Severity   string // error if empty
+	Fix        func() // nil if the issue can't be fixed automatically
+}
+
+var componentTypes = []string{"backend", "admin", "store", "docker"}
+
+func lintManifest(path string, manifest map[string]interface{}) []LintIssue {
+	var issues []LintIssue
+	data, err := os.ReadFile(filepath.Join(path, "manifest.json"))
+	checkErr(err)
+	if manifestSchema, ok := manifest["$schema"].(string); !ok || manifestSchema != schemaURL {
+		issues = append(issues, LintIssue{
+			Rule:       "manifest-schema",
+			File:       "manifest.json",
+			Message:    fmt.Sprintf("$schema is not set to the current schema %s", schemaURL),
+			Suggestion: "Update $schema to the current version",
+			Fix:        func() { manifest["$schema"] = schemaURL },
+		})
+	}
+	if !bytes.Equal(data, formatManifest(manifest)) {
+		issues = append(issues, LintIssue{
+			Rule:       "manifest-format",
+			File:       "manifest.json",
+			Message:    "manifest.json is not formatted canonically",
+			Suggestion: "Use 2-space indentation and the standard key order",
+		})
+	}
+	if len(issues) > 0 {
+		// formatting fix writes the manifest after all other manifest fixes are applied
+		issues[len(issues)-1].Fix = chainFixes(issues[len(issues)-1].Fix, func() { writeManifest(path, manifest) })
+	}
+	return issues
+}
+
+func chainFixes(fixes ...func()) func() {
+	return func() {
+		for _, fix := range fixes {
+			if fix != nil {
+				fix()
+			}
+		}
+	}
+}
+
+func lintProjectFiles(path string, manifest map[string]interface{}) []LintIssue {
+	var issues []LintIssue
+	if !exists(filepath.Join(path, ".editorconfig")) {
+		issues = append(issues, LintIssue{
+			Rule:       "missing-file",
+			File:       ".editorconfig",
+			Message:    ".editorconfig is missing",
+			Suggestion: "Add the default .editorconfig",
+			Fix:        func() { copyFileContents("plugin/.editorconfig", filepath.Join(path, ".editorconfig")) },
+		})
+	}
+	if !exists(filepath.Join(path, ".gitignore")) {
+		issues = append(issues, LintIssue{
+			Rule:       "missing-file",
+			File:       ".gitignore",
+			Message:    ".gitignore is missing",
+			Suggestion: "Add the default .gitignore",
+			Fix: func() {
+				checkErr(
+					os.WriteFile(
+						filepath.Join(path, ".gitignore"),
+						executeTemplate("plugin/.gitignore.tmpl", manifest, true),
+						os.ModePerm,
+					),
+				)
+			},
+		})
+	}
+	return issues
+}
+
+func lintComponents(path string, manifest map[string]interface{}) []LintIssue {
+	var issues []LintIssue
+	used := map[string]bool{}
+	iterateInstallations(path, manifest, func(componentPath, componentName, installType string) {
+		used[filepath.Clean(componentPath)] = true
+		file := relativeToPlugin(path, componentPath)
+		if !isDir(componentPath) {
+			issues = append(issues, LintIssue{
+				Rule:       "stale-component",
+				File:       "manifest.json",
+				Message:    fmt.Sprintf("Component %s does not exist", file),
+				Suggestion: "Create the component directory or remove it from installs",
+			})
+			return
+		}
+		if installType == "backend" && !exists(filepath.Join(componentPath, "__init__.py")) {
+			issues = append(issues, LintIssue{
+				Rule:       "missing-init",
+				File:       file + "/__init__.py",
+				Message:    "Backend component is missing __init__.py",
+				Suggestion: "Add an empty __init__.py",
+				Fix:        func() { createInitPyFile(componentPath) },
+			})
+		}
+	})
+	for _, componentType := range componentTypes {
+		entries, err := os.ReadDir(filepath.Join(path, "src", componentType))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			componentPath := filepath.Join(path, "src", componentType, entry.Name())
+			if entry.IsDir() && !used[componentPath] {
+				issues = append(issues, LintIssue{
+					Rule:       "unused-component",
+					File:       relativeToPlugin(path, componentPath),
+					Message:    "Component is not listed in manifest installs",
+					Suggestion: "Add it to installs or remove the directory",
+					Severity:   "warning",
+				})
+			}
+		}
+	}
+	return issues
+}
+
+func lintPlugin(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path, err := filepath.Abs(args.Get(0))
+	checkErr(err)
+	manifest := readManifest(path).(map[string]interface{})
+	issues := lintManifest(path, manifest)
+	issues = append(issues, lintProjectFiles(path, manifest)...)
+	if hasValidInstalls(manifest) {
+		issues = append(issues, lintComponents(path, manifest)...)
+	}
+	report := &ValidationReport{Path: args.Get(0), SuccessMessage: "No lint issues found"}
+	report.addLintIssues(issues, cmd.Bool("fix"))
+	report.Print(cmd.String("format"))
+	return nil
+}
+
+// addLintIssues adds issues to the report, or fixes them instead if possible and requested
+func (report *ValidationReport) addLintIssues(issues []LintIssue, fix bool) {
+	for _, issue := range issues {
+		if fix && issue.Fix != nil {
+			issue.Fix()
+			fmt.Fprintf(os.Stderr, "fixed: %s: %s\n", issue.File, issue.Message)
+			continue
+		}
+		severity := issue.Severity
+		if severity == "" {
+			severity = "error"
+		}
+		report.Add(ValidationIssue{issue.Rule, severity, issue.File, "", issue.Message, issue.Suggestion})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+)
+
+type PlanEntry struct {
+	Action      string `json:"action"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Size        int64  `json:"size"`
+}
+
+func pathSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error { // nolint:errcheck
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func printPlan(entries []PlanEntry, format string) {
+	if format == "json" {
+		if entries == nil {
+			entries = []PlanEntry{}
+		}
+		smartPrint(jsonEncode(entries))
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println("Nothing to do")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tSOURCE\tDESTINATION\tSIZE")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Action, entry.Source, entry.Destination, formatSize(entry.Size))
+	}
+	w.Flush()
+}
+
+func existingPathEntry(action string, path string) []PlanEntry {
+	if _, err := os.Lstat(path); err != nil {
+		return nil
+	}
+	return []PlanEntry{{Action: action, Destination: path, Size: pathSize(path)}}
+}
+
+func planInstall(sources []*PluginSource, rootSource *PluginSource, isDev bool) []PlanEntry {
+	var entries []PlanEntry
+	for _, source := range sources {
+		action := "copy"
+		if isDev && source == rootSource {
+			action = "symlink"
+		}
+		for _, component := range resolvePluginComponents(source.Path, source.Manifest) {
+			if component.Type == "backend" {
+				initPy := filepath.Join(filepath.Dir(component.Final), "__init__.py")
+				if !exists(initPy) {
+					entries = append(entries, PlanEntry{Action: "create", Destination: initPy})
+				}
+			}
+			entries = append(entries, existingPathEntry("delete", component.Final)...)
+			entries = append(entries, PlanEntry{
+				Action:      action,
+				Source:      component.Source,
+				Destination: component.Final,
+				Size:        pathSize(component.Source),
+			})
+		}
+	}
+	return entries
+}
+
+func planUninstall(source *PluginSource) []PlanEntry {
+	var entries []PlanEntry
+	for _, component := range resolvePluginComponents(source.Path, source.Manifest) {
+		entries = append(entries, existingPathEntry("delete", component.Final)...)
+	}
+	return entries
+}
+
+func planPackage(path string, outPath string, exclude RejectByNameFunc) []PlanEntry {
+	var entries []PlanEntry
+	var total int64
+	included, excluded := collectPackageFiles(path, outPath, exclude)
+	for _, relPath := range excluded {
+		filePath := filepath.Join(path, filepath.FromSlash(relPath))
+		entries = append(entries, PlanEntry{Action: "exclude", Source: filePath, Size: pathSize(filePath)})
+	}
+	for _, relPath := range included {
+		filePath := filepath.Join(path, filepath.FromSlash(relPath))
+		size := pathSize(filePath)
+		entries = append(entries, PlanEntry{
+			Action:      "include",
+			Source:      filePath,
+			Destination: relPath,
+			Size:        size,
+		})
+		total += size
+	}
+	return append(entries, PlanEntry{Action: "write", Destination: outPath, Size: total})
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/urfave/cli/v3"
+)
+
+const changelogFile = "CHANGELOG.md"
+
+var packageVersionRegex = regexp.MustCompile(`("version"\s*:\s*")[^"]*(")`)
+
+// bumpVersion increments the version by major, minor or patch, or parses an explicit version
+func bumpVersion(current semver.Version, bump string) (semver.Version, error) {
+	switch bump {
+	case "major":
+		return semver.Version{Major: current.Major + 1}, nil
+	case "minor":
+		return semver.Version{Major: current.Major, Minor: current.Minor + 1}, nil
+	case "patch":
+		if len(current.Pre) > 0 {
+			// 1.0.1-rc.1 is released as 1.0.1
+			return semver.Version{Major: current.Major, Minor: current.Minor, Patch: current.Patch}, nil
+		}
+		return semver.Version{Major: current.Major, Minor: current.Minor, Patch: current.Patch + 1}, nil
+	}
+	return semver.Parse(strings.TrimPrefix(bump, "v"))
+}
+
+// updatePackageVersion replaces the version in package.json keeping the rest of the file as is
+func updatePackageVersion(path string, version string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	match := packageVersionRegex.FindSubmatchIndex(data)
+	if match == nil {
+		return false
+	}
+	data = slices.Concat(data[:match[3]], []byte(version), data[match[4]:])
+	checkErr(os.WriteFile(path, data, os.ModePerm))
+	return true
+}
+
+// releaseTagMatcher returns a function checking whether a tag name is a release of the plugin, i.e. it
+// matches the tag format with any version, so that tags of other plugins in the repository are ignored
+func releaseTagMatcher(manifest map[string]interface{}, tagFormat string) func(string) bool {
+	// NUL can't appear in tag names, so it safely marks the version position
+	pattern := regexp.QuoteMeta(expandManifestTemplate(manifest, "\x00", tagFormat))
+	tagRegex := regexp.MustCompile("^" + strings.ReplaceAll(pattern, "\x00", "(.+)") + "$")
+	return func(tagName string) bool {
+		match := tagRegex.FindStringSubmatch(tagName)
+		if match == nil {
+			return false
+		}
+		for _, version := range match[1:] {
+			if _, err := semver.ParseTolerant(version); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// getCommitsSinceTag returns subjects of commits touching the plugin directory since the last release tag
+func getCommitsSinceTag(repo *git.Repository, relPath string, isReleaseTag func(string) bool) []string {
+	tagged := map[plumbing.Hash]bool{}
+	tags, err := repo.Tags()
+	checkErr(err)
+	checkErr(tags.ForEach(func(ref *plumbing.Reference) error {
+		if !isReleaseTag(ref.Name().Short()) {
+			return nil
+		}
+		hash := ref.Hash()
+		if tag, err := repo.TagObject(hash); err == nil {
+			if commit, err := tag.Commit(); err == nil {
+				hash = commit.Hash
+			}
+		}
+		tagged[hash] = true
+		return nil
+	}))
+	head, err := repo.Head()
+	checkErr(err)
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	checkErr(err)
+	var entries []string
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if tagged[commit.Hash] {
+			return storer.ErrStop
+		}
+		if relPath != "." {
+			stats, err := commit.Stats()
+			if err != nil {
+				return err
+			}
+			if !slices.ContainsFunc(stats, func(stat object.FileStat) bool {
+				return strings.HasPrefix(stat.Name, relPath+"/")
+			}) {
+				return nil
+			}
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		entries = append(entries, fmt.Sprintf("%s (%s)", subject, commit.Hash.String()[:7]))
+		return nil
+	})
+	checkErr(err)
+	return entries
+}
+
+func prependChangelog(path string, version string, entries []string) {
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "## %s (%s)\n\n", version, time.Now().Format("2006-01-02"))
+	if len(entries) == 0 {
+		entry.WriteString("- No changes recorded\n")
+	}
+	for _, line := range entries {
+		fmt.Fprintf(&entry, "- %s\n", line)
+	}
+	entry.WriteString("\n")
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		checkErr(err)
+	}
+	content := string(data)
+	header := "# Changelog\n\n"
+	if content == "" {
+		content = header
+	}
+	if strings.HasPrefix(content, header) {
+		content = header + entry.String() + strings.TrimPrefix(content, header)
+	} else {
+		content = entry.String() + content
+	}
+	checkErr(os.WriteFile(path, []byte(content), os.ModePerm))
+}
+
+func versionPlugin(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 2 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path, err := filepath.Abs(args.Get(0))
+	checkErr(err)
+	manifest := readManifest(path).(map[string]interface{})
+	currentString, _ := manifest["version"].(string)
+	current, err := semver.ParseTolerant(currentString)
+	checkErr(err)
+	version, err := bumpVersion(current, args.Get(1))
+	checkErr(err)
+	if version.LTE(current) {
+		message := fmt.Sprintf("new version %s is not newer than %s", version, current)
+		failUnlessForced(message, "use --force to set it anyway", cmd.Bool("force"))
+	}
+	changelog, tag := cmd.Bool("changelog"), cmd.Bool("tag")
+	var repo *git.Repository
+	var root, relPath string
+	if changelog || tag {
+		repo, err = git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+		if err != nil {
+			exitErr(fmt.Sprintf("Error: %s is not inside a git repository: %s", path, err))
+		}
+		worktree, err := repo.Worktree()
+		checkErr(err)
+		root = worktree.Filesystem.Root()
+		relPath, err = filepath.Rel(root, path)
+		checkErr(err)
+		relPath = filepath.ToSlash(relPath)
+	}
+	tagName := expandManifestTemplate(manifest, version.String(), cmd.String("tag-format"))
+	if tag {
+		if _, err := repo.Tag(tagName); err == nil {
+			exitErr(fmt.Sprintf("Error: tag %s already exists", tagName))
+		}
+	}
+	changed := []string{filepath.Join(path, "manifest.json")}
+	if changelog {
+		entries := getCommitsSinceTag(repo, relPath, releaseTagMatcher(manifest, cmd.String("tag-format")))
+		prependChangelog(filepath.Join(path, changelogFile), version.String(), entries)
+		changed = append(changed, filepath.Join(path, changelogFile))
+	}
+	manifest["version"] = version.String()
+	writeManifest(path, manifest)
+	if hasValidInstalls(manifest) {
+		iterateInstallations(path, manifest, func(componentPath, componentName, installType string) {
+			packagePath := filepath.Join(componentPath, "package.json")
+			if (installType == "admin" || installType == "store") && updatePackageVersion(packagePath, version.String()) {
+				changed = append(changed, packagePath)
+			}
+		})
+	}
+	for _, file := range changed {
+		fmt.Println("Updated", file)
+	}
+	if tag {
+		worktree, err := repo.Worktree()
+		checkErr(err)
+		for _, file := range changed {
+			relFile, err := filepath.Rel(root, file)
+			checkErr(err)
+			_, err = worktree.Add(filepath.ToSlash(relFile))
+			checkErr(err)
+		}
+		message := fmt.Sprintf("Release %s %s", pluginKey(manifest), version)
+		commit, err := worktree.Commit(message, &git.CommitOptions{})
+		checkErr(err)
+		_, err = repo.CreateTag(tagName, commit, &git.CreateTagOptions{Message: message})
+		checkErr(err)
+		fmt.Printf("Committed changes and created tag %s\n", tagName)
+	}
+	fmt.Printf("Version bumped from %s to %s\n", current, version)
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+type ValidationIssue struct {
+	Rule       string `json:"rule"`
+	Severity   string `json:"severity"`
+	File       string `json:"file"`
+	Location   string `json:"location,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+type ValidationReport struct {
+	Path   string            `json:"path"`
+	Valid  bool              `json:"valid"`
+	Issues []ValidationIssue `json:"issues"`
+	// printed in text mode when there are no errors, defaults to "Plugin is valid!"
+	SuccessMessage string `json:"-"`
+}
+
+func (report *ValidationReport) Add(issue ValidationIssue) {
+	report.Issues = append(report.Issues, issue)
+}
+
+func (report *ValidationReport) Error(rule, file, location, message, suggestion string) {
+	report.Add(ValidationIssue{rule, "error", file, location, message, suggestion})
+}
+
+func (report *ValidationReport) Warning(rule, file, location, message, suggestion string) {
+	report.Add(ValidationIssue{rule, "warning", file, location, message, suggestion})
+}
+
+func (report *ValidationReport) Count(severity string) int {
+	count := 0
+	for _, issue := range report.Issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+var schemaSuggestions = map[string]string{
+	"required":             "Add the missing property",
+	"enum":                 "Use one of the allowed values",
+	"type":                 "Change the value to the expected type",
+	"minLength":            "Provide a non-empty value",
+	"pattern":              "Change the value to match the expected format",
+	"propertyNames":        "Rename the key to match the expected format",
+	"additionalProperties": "Remove the unknown property",
+}
+
+// addSchemaErrors converts leaf schema validation errors to report issues
+func (report *ValidationReport) addSchemaErrors(err error) {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		report.Error("schema", "manifest.json", "", err.Error(), "")
+		return
+	}
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		suggestion, ok := schemaSuggestions[path.Base(e.KeywordLocation)]
+		if !ok {
+			suggestion = "Fix the value to match the manifest schema"
+		}
+		report.Error("schema", "manifest.json", e.InstanceLocation, e.Message, suggestion)
+	}
+	walk(validationErr)
+}
+
+func (report *ValidationReport) Print(format string) {
+	report.Valid = report.Count("error") == 0
+	if report.Issues == nil {
+		report.Issues = []ValidationIssue{}
+	}
+	switch format {
+	case "json":
+		smartPrint(jsonEncode(report))
+	case "sarif":
+		smartPrint(jsonEncode(report.toSARIF()))
+	default:
+		for _, issue := range report.Issues {
+			location := issue.File
+			if issue.Location != "" {
+				location += "#" + issue.Location
+			}
+			fmt.Printf("%s: %s: %s\n", issue.Severity, location, issue.Message)
+			if issue.Suggestion != "" {
+				fmt.Printf("  suggestion: %s\n", issue.Suggestion)
+			}
+		}
+		if report.Valid {
+			if report.SuccessMessage == "" {
+				report.SuccessMessage = "Plugin is valid!"
+			}
+			fmt.Println(report.SuccessMessage)
+		} else {
+			fmt.Printf("%d error(s), %d warning(s)\n", report.Count("error"), report.Count("warning"))
+		}
+	}
+	if !report.Valid {
+		os.Exit(1)
+	}
+}
+
+func (report *ValidationReport) toSARIF() map[string]interface{} {
+	rules := []interface{}{}
+	seenRules := map[string]bool{}
+	results := []interface{}{}
+	for _, issue := range report.Issues {
+		if !seenRules[issue.Rule] {
+			seenRules[issue.Rule] = true
+			rules = append(rules, map[string]interface{}{"id": issue.Rule})
+		}
+		message := issue.Message
+		if issue.Suggestion != "" {
+			message += ". " + issue.Suggestion
+		}
+		location := map[string]interface{}{
+			"physicalLocation": map[string]interface{}{
+				"artifactLocation": map[string]interface{}{"uri": issue.File},
+			},
+		}
+		if issue.Location != "" {
+			location["logicalLocations"] = []interface{}{
+				map[string]interface{}{"fullyQualifiedName": issue.Location},
+			}
+		}
+		results = append(results, map[string]interface{}{
+			"ruleId":    issue.Rule,
+			"level":     issue.Severity,
+			"message":   map[string]interface{}{"text": message},
+			"locations": []interface{}{location},
+		})
+	}
+	return map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           "bitcart-cli",
+						"version":        Version,
+						"informationUri": "https://github.com/bitcart/bitcart-cli",
+						"rules":          rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+}
+
+// relativeToPlugin returns a slash-separated path relative to the plugin root for report locations
+func relativeToPlugin(root string, target string) string {
+	if rel, err := filepath.Rel(root, target); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return target
+}
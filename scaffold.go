@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/urfave/cli/v3"
+)
+
+func askComponentName(componentType string) string {
+	var componentName string
+	checkErr(
+		survey.AskOne(
+			&survey.Input{
+				Message: fmt.Sprintf("Enter name of your %s component (i.e. name of the subfolder)", componentType),
+			},
+			&componentName,
+			survey.WithValidator(survey.Required),
+			survey.WithValidator(componentNameValidator),
+		),
+	)
+	return componentName
+}
+
+func checkComponentName(name string) {
+	if err := componentNameValidator(name); err != nil {
+		exitErr(fmt.Sprintf("Error: invalid component name %q: %s", name, err))
+	}
+}
+
+func writeTemplate(dest string, templatePath string, data interface{}) {
+	checkErr(os.WriteFile(dest, executeTemplate(templatePath, data, false), os.ModePerm))
+}
+
+var componentFeatures = map[string][]string{
+	"backend": {"router", "models", "migrations", "settings", "requirements", "tests"},
+	"admin":   {"tests"},
+	"store":   {"tests"},
+}
+
+// askComponentFeatures returns optional files to generate, from --features or asked interactively
+func askComponentFeatures(cmd *cli.Command, componentType string) []string {
+	options := componentFeatures[componentType]
+	if len(options) == 0 {
+		return nil
+	}
+	var features []string
+	if cmd.IsSet("features") {
+		for _, feature := range cmd.StringSlice("features") {
+			if feature != "" {
+				features = append(features, feature)
+			}
+		}
+	} else {
+		checkErr(survey.AskOne(&survey.MultiSelect{
+			Message: fmt.Sprintf("Select extra files for your %s component", componentType),
+			Options: options,
+		}, &features))
+	}
+	for _, feature := range features {
+		if !slices.Contains(options, feature) {
+			exitErr(fmt.Sprintf("Error: unknown %s feature %q, expected one of %v", componentType, feature, options))
+		}
+	}
+	if slices.Contains(features, "migrations") && !slices.Contains(features, "models") {
+		features = append(features, "models")
+	}
+	return features
+}
+
+// scaffoldComponent creates src/<type>/<name> from the embedded templates and returns its manifest entry
+func scaffoldComponent(
+	path string,
+	author string,
+	componentType string,
+	componentName string,
+	features []string,
+) ComponentType {
+	component := ComponentType{Type: componentType, Path: "src/" + componentType + "/" + componentName}
+	internalPath := filepath.Join(path, component.Path)
+	createIfNotExists(internalPath, os.ModePerm)
+	data := struct {
+		Author   string
+		Name     string
+		Features map[string]bool
+	}{Author: author, Name: componentName, Features: map[string]bool{}}
+	for _, feature := range features {
+		data.Features[feature] = true
+	}
+	switch componentType {
+	case "backend":
+		writeTemplate(filepath.Join(internalPath, "plugin.py"), "plugin/src/backend/plugin.py.tmpl", data)
+		createInitPyFile(internalPath)
+		for _, feature := range []string{"router", "models", "settings"} {
+			if data.Features[feature] {
+				writeTemplate(
+					filepath.Join(internalPath, feature+".py"),
+					"plugin/src/backend/"+feature+".py.tmpl",
+					data,
+				)
+			}
+		}
+		if data.Features["requirements"] {
+			writeTemplate(
+				filepath.Join(internalPath, "requirements.txt"),
+				"plugin/src/backend/requirements.txt.tmpl",
+				data,
+			)
+		}
+		if data.Features["migrations"] {
+			createIfNotExists(filepath.Join(internalPath, "versions"), os.ModePerm)
+			checkErr(os.WriteFile(filepath.Join(internalPath, "versions", ".gitkeep"), []byte(""), os.ModePerm))
+		}
+		if data.Features["tests"] {
+			createInitPyFile(filepath.Join(internalPath, "tests"))
+			for _, file := range []string{"conftest.py", "test_plugin.py"} {
+				writeTemplate(
+					filepath.Join(internalPath, "tests", file),
+					"plugin/src/backend/tests/"+file+".tmpl",
+					data,
+				)
+			}
+		}
+	case "docker":
+		writeTemplate(
+			filepath.Join(internalPath, "docker-compose.yml"),
+			"plugin/src/docker/docker-compose.yml.tmpl",
+			data,
+		)
+	case "admin", "store":
+		createIfNotExists(filepath.Join(internalPath, "config"), os.ModePerm)
+		for _, file := range []string{"index.js", "config/extends.js", "config/routes.js"} {
+			copyFileContents(filepath.Join("plugin/src/frontend", file), filepath.Join(internalPath, file))
+		}
+		writeTemplate(filepath.Join(internalPath, "package.json"), "plugin/src/frontend/package.json.tmpl", data)
+		writeTemplate(
+			filepath.Join(internalPath, "config/index.js"),
+			"plugin/src/frontend/config/index.js.tmpl",
+			data,
+		)
+		if data.Features["tests"] {
+			createIfNotExists(filepath.Join(internalPath, "tests"), os.ModePerm)
+			writeTemplate(
+				filepath.Join(internalPath, "tests", "config.test.js"),
+				"plugin/src/frontend/tests/config.test.js.tmpl",
+				data,
+			)
+		}
+	}
+	return component
+}
+
+// linkDevComponent symlinks the component into the configured repository, like install --dev does
+func linkDevComponent(componentPath string, author string, componentType string, componentName string) {
+	askComponentDirectory(componentType)
+	repoPath := getComponentConfigEntry(componentType)
+	var err error
+	*repoPath, err = filepath.Abs(*repoPath)
+	checkErr(err)
+	outputPath := filepath.Join(*repoPath, getOutputDirectory(componentType, author, componentName))
+	if componentType == "backend" {
+		createInitPyFile(filepath.Dir(outputPath))
+	}
+	safeSymlink(componentPath, outputPath)
+}
+
+func addComponent(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path, err := filepath.Abs(args.Get(0))
+	checkErr(err)
+	manifest := readManifest(path).(map[string]interface{})
+	loadTemplate(cmd.String("template"), cmd.StringSlice("var"))
+	componentType := cmd.String("type")
+	if !slices.Contains(componentTypes, componentType) {
+		exitErr(fmt.Sprintf("Error: unknown component type %q, expected one of %v", componentType, componentTypes))
+	}
+	componentName := cmd.String("name")
+	if componentName == "" {
+		componentName = askComponentName(componentType)
+	}
+	checkComponentName(componentName)
+	author := manifest["author"].(string)
+	installs, _ := manifest["installs"].([]interface{})
+	componentPath := filepath.Join(path, "src", componentType, componentName)
+	if exists(componentPath) {
+		exitErr(fmt.Sprintf("Error: %s already exists", componentPath))
+	}
+	if installs != nil {
+		iterateInstallations(path, manifest, func(existingPath, existingName, installType string) {
+			if installType == componentType && existingName == componentName {
+				exitErr(fmt.Sprintf("Error: %s component %s is already listed in installs", componentType, componentName))
+			}
+		})
+	}
+	askTemplatePrompts()
+	features := askComponentFeatures(cmd, componentType)
+	component := scaffoldComponent(path, author, componentType, componentName, features)
+	renderTemplateFiles(path, TemplateData{
+		Author:      author,
+		Name:        componentName,
+		Plugin:      fmt.Sprint(manifest["name"]),
+		Description: fmt.Sprint(manifest["description"]),
+		Type:        componentType,
+	})
+	manifest["installs"] = append(installs, map[string]interface{}{"type": component.Type, "path": component.Path})
+	writeManifest(path, manifest)
+	if !cmd.Bool("no-link") {
+		linkDevComponent(componentPath, author, componentType, componentName)
+	}
+	if cmd.Bool("save") {
+		rootOptions.WriteToDisk()
+	}
+	fmt.Printf("Added %s component %s\n", componentType, componentName)
+	return nil
+}
+
+// findComponent returns the index of the component in manifest installs
+func findComponent(path string, manifest map[string]interface{}, componentType string, componentName string) int {
+	if !hasValidInstalls(manifest) {
+		exitErr("Error: manifest does not have valid installs")
+	}
+	found, index := -1, 0
+	iterateInstallations(path, manifest, func(componentPath, existingName, installType string) {
+		if installType == componentType && existingName == componentName {
+			found = index
+		}
+		index++
+	})
+	if found == -1 {
+		exitErr(fmt.Sprintf("Error: %s component %s is not listed in installs", componentType, componentName))
+	}
+	return found
+}
+
+// getDevLink returns the path of the component symlink in the configured repository, if the symlink points to it
+func getDevLink(componentPath string, author string, componentType string, componentName string) string {
+	repoPath := getComponentConfigEntry(componentType)
+	if *repoPath == "" {
+		return ""
+	}
+	linkPath := filepath.Join(*repoPath, getOutputDirectory(componentType, author, componentName))
+	target, err := os.Readlink(linkPath)
+	if err != nil || filepath.Clean(target) != filepath.Clean(componentPath) {
+		return ""
+	}
+	return linkPath
+}
+
+func removeDevLink(linkPath string, componentType string) {
+	checkErr(os.Remove(linkPath))
+	if componentType == "backend" {
+		removeOrgInitIfNoPlugins(filepath.Dir(linkPath))
+	}
+}
+
+func removeComponent(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path, err := filepath.Abs(args.Get(0))
+	checkErr(err)
+	manifest := readManifest(path).(map[string]interface{})
+	componentType := cmd.String("type")
+	componentName := cmd.String("name")
+	author := manifest["author"].(string)
+	index := findComponent(path, manifest, componentType, componentName)
+	installs := manifest["installs"].([]interface{})
+	componentPath := filepath.Join(path, installs[index].(map[string]interface{})["path"].(string))
+	checkComponentInsidePlugin(path, componentPath)
+	if linkPath := getDevLink(componentPath, author, componentType, componentName); linkPath != "" {
+		removeDevLink(linkPath, componentType)
+	}
+	manifest["installs"] = slices.Delete(installs, index, index+1)
+	writeManifest(path, manifest)
+	if !cmd.Bool("keep-files") {
+		checkErr(os.RemoveAll(componentPath))
+	}
+	fmt.Printf("Removed %s component %s\n", componentType, componentName)
+	return nil
+}
+
+// checkComponentInsidePlugin refuses to modify components which manifest points outside of the plugin
+func checkComponentInsidePlugin(root string, componentPath string) {
+	if !isInsideRoot(root, componentPath) {
+		exitErr(fmt.Sprintf("Error: component path %s is outside of the plugin directory", componentPath))
+	}
+}
+
+// renamePluginClass updates the name attribute of the Plugin class in plugin.py
+func renamePluginClass(pluginPath string, newName string) {
+	data, err := os.ReadFile(pluginPath)
+	if err != nil {
+		return
+	}
+	classLocation := pluginClassRegex.FindIndex(data)
+	if classLocation == nil {
+		return
+	}
+	match := pluginNameRegex.FindSubmatchIndex(data[classLocation[1]:])
+	if match == nil {
+		return
+	}
+	start, end := classLocation[1]+match[2], classLocation[1]+match[3]
+	data = slices.Concat(data[:start], []byte(newName), data[end:])
+	checkErr(os.WriteFile(pluginPath, data, os.ModePerm))
+}
+
+func replaceInComponentFiles(componentPath string, files []string, replacer *strings.Replacer) {
+	for _, file := range files {
+		filePath := filepath.Join(componentPath, file)
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		checkErr(os.WriteFile(filePath, []byte(replacer.Replace(string(data))), os.ModePerm))
+	}
+}
+
+// renameComponentFiles updates files which depend on the component name, including the generated
+// router, settings and tests
+func renameComponentFiles(componentPath string, author string, componentType string, oldName string, newName string) {
+	switch componentType {
+	case "backend":
+		renamePluginClass(filepath.Join(componentPath, "plugin.py"), newName)
+		oldPrefix, newPrefix := strings.ToUpper(oldName)+"_", strings.ToUpper(newName)+"_"
+		replaceInComponentFiles(
+			componentPath,
+			[]string{"plugin.py", "router.py", "settings.py", "tests/test_plugin.py"},
+			strings.NewReplacer(
+				fmt.Sprintf("\"/plugins/%s\"", oldName), fmt.Sprintf("\"/plugins/%s\"", newName),
+				fmt.Sprintf("tags=[\"%s\"]", oldName), fmt.Sprintf("tags=[\"%s\"]", newName),
+				fmt.Sprintf("\"plugin\": \"%s\"", oldName), fmt.Sprintf("\"plugin\": \"%s\"", newName),
+				fmt.Sprintf("name == \"%s\"", oldName), fmt.Sprintf("name == \"%s\"", newName),
+				fmt.Sprintf("env_prefix=\"%s\"", oldPrefix), fmt.Sprintf("env_prefix=\"%s\"", newPrefix),
+				fmt.Sprintf("i.e. %sENABLED", oldPrefix), fmt.Sprintf("i.e. %sENABLED", newPrefix),
+			),
+		)
+	case "admin", "store":
+		replaceInComponentFiles(
+			componentPath,
+			[]string{"package.json", "config/index.js", "config/routes.js", "config/extends.js", "tests/config.test.js"},
+			strings.NewReplacer(
+				fmt.Sprintf("\"@%s/%s\"", author, oldName), fmt.Sprintf("\"@%s/%s\"", author, newName),
+				fmt.Sprintf("\"@%s-%s\"", author, oldName), fmt.Sprintf("\"@%s-%s\"", author, newName),
+				// imports and route names added by plugin generate
+				fmt.Sprintf("\"@%s-%s/", author, oldName), fmt.Sprintf("\"@%s-%s/", author, newName),
+				fmt.Sprintf("name: \"%s-", oldName), fmt.Sprintf("name: \"%s-", newName),
+				fmt.Sprintf("\"%s plugin config\"", oldName), fmt.Sprintf("\"%s plugin config\"", newName),
+			),
+		)
+	}
+}
+
+func renameComponent(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path, err := filepath.Abs(args.Get(0))
+	checkErr(err)
+	manifest := readManifest(path).(map[string]interface{})
+	componentType := cmd.String("type")
+	oldName := cmd.String("name")
+	newName := cmd.String("to")
+	checkComponentName(newName)
+	author := manifest["author"].(string)
+	index := findComponent(path, manifest, componentType, oldName)
+	installData := manifest["installs"].([]interface{})[index].(map[string]interface{})
+	oldPath := filepath.Join(path, installData["path"].(string))
+	checkComponentInsidePlugin(path, oldPath)
+	newRelPath := filepath.ToSlash(filepath.Join(filepath.Dir(installData["path"].(string)), newName))
+	newPath := filepath.Join(path, newRelPath)
+	if exists(newPath) {
+		exitErr(fmt.Sprintf("Error: %s already exists", newPath))
+	}
+	linkPath := getDevLink(oldPath, author, componentType, oldName)
+	checkErr(os.Rename(oldPath, newPath))
+	renameComponentFiles(newPath, author, componentType, oldName, newName)
+	installData["path"] = newRelPath
+	writeManifest(path, manifest)
+	if linkPath != "" {
+		removeDevLink(linkPath, componentType)
+		linkDevComponent(newPath, author, componentType, newName)
+	}
+	fmt.Printf("Renamed %s component %s to %s\n", componentType, oldName, newName)
+	return nil
+}
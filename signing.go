@@ -0,0 +1,225 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/crypto/ssh"
+)
+
+const signatureDir = ".bitcart"
+
+var (
+	checksumsFile = signatureDir + "/checksums.json"
+	signatureFile = signatureDir + "/signature.json"
+)
+
+var errUnsigned = errors.New("Archive is not signed")
+
+type PackageSignature struct {
+	PublicKey string `json:"public_key"`
+	Signature string `json:"signature"`
+}
+
+type SignatureInfo struct {
+	PublicKey   ssh.PublicKey
+	Fingerprint string
+	Trusted     bool
+}
+
+// loadSigningKey reads an ed25519 private key in OpenSSH or PKCS#8 PEM format
+func loadSigningKey(path string) ed25519.PrivateKey {
+	data, err := os.ReadFile(path)
+	checkErr(err)
+	key, err := ssh.ParseRawPrivateKey(data)
+	checkErr(err)
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		return key
+	case *ed25519.PrivateKey:
+		return *key
+	}
+	exitErr("Error: only ed25519 keys are supported for signing")
+	return nil
+}
+
+// parseTrustedKey parses a public key in authorized_keys format (i.e. ssh-ed25519 AAAA...)
+func parseTrustedKey(key string) (ssh.PublicKey, error) {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid public key %q: %w", key, err)
+	}
+	if publicKey.Type() != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("Unsupported key type %s, only ed25519 keys are supported", publicKey.Type())
+	}
+	return publicKey, nil
+}
+
+func buildSignatureFiles(checksums map[string]string, key ed25519.PrivateKey) map[string][]byte {
+	checksumsData := []byte(jsonEncode(checksums))
+	publicKey, err := ssh.NewPublicKey(key.Public())
+	checkErr(err)
+	signature := PackageSignature{
+		PublicKey: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, checksumsData)),
+	}
+	return map[string][]byte{
+		checksumsFile: checksumsData,
+		signatureFile: []byte(jsonEncode(signature)),
+	}
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	reader, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func hashZipEntry(f *zip.File) (string, error) {
+	reader, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verifyArchive checks the archive signature and that contents match the signed checksums.
+// Returns errUnsigned if the archive has no signature
+func verifyArchive(path string, trustedKeys []string) (*SignatureInfo, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var checksumsData, signatureData []byte
+	actual := map[string]string{}
+	for _, f := range r.File {
+		switch {
+		case f.Name == checksumsFile:
+			checksumsData, err = readZipEntry(f)
+		case f.Name == signatureFile:
+			signatureData, err = readZipEntry(f)
+		case f.FileInfo().IsDir():
+			continue
+		default:
+			actual[f.Name], err = hashZipEntry(f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if checksumsData == nil || signatureData == nil {
+		return nil, errUnsigned
+	}
+	var signature PackageSignature
+	if err := json.Unmarshal(signatureData, &signature); err != nil {
+		return nil, fmt.Errorf("Invalid signature file: %w", err)
+	}
+	publicKey, err := parseTrustedKey(signature.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	rawSignature, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid signature encoding: %w", err)
+	}
+	rawKey := publicKey.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey)
+	if !ed25519.Verify(rawKey, checksumsData, rawSignature) {
+		return nil, errors.New("Signature verification failed")
+	}
+	var expected map[string]string
+	if err := json.Unmarshal(checksumsData, &expected); err != nil {
+		return nil, fmt.Errorf("Invalid checksums file: %w", err)
+	}
+	for _, name := range sortedKeys(actual) {
+		checksum, ok := expected[name]
+		if !ok {
+			return nil, fmt.Errorf("File %s is not covered by the signature", name)
+		}
+		if checksum != actual[name] {
+			return nil, fmt.Errorf("Checksum mismatch for %s", name)
+		}
+	}
+	for _, name := range sortedKeys(expected) {
+		if _, ok := actual[name]; !ok {
+			return nil, fmt.Errorf("Signed file %s is missing from the archive", name)
+		}
+	}
+	info := &SignatureInfo{PublicKey: publicKey, Fingerprint: ssh.FingerprintSHA256(publicKey)}
+	for _, trustedKey := range trustedKeys {
+		trusted, err := parseTrustedKey(trustedKey)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Equal(trusted.Marshal(), publicKey.Marshal()) {
+			info.Trusted = true
+			break
+		}
+	}
+	return info, nil
+}
+
+// checkArchiveSignature applies the trust policy before installing from an archive: integrity failures are
+// always fatal, while unsigned archives or unknown keys are refused only if trusted keys are configured
+func checkArchiveSignature(path string, allowUntrusted bool) {
+	info, err := verifyArchive(path, rootOptions.TrustedKeys)
+	if err != nil && !errors.Is(err, errUnsigned) {
+		exitErr(fmt.Sprintf("Error: %s: %s", path, err))
+	}
+	var message string
+	switch {
+	case err != nil && len(rootOptions.TrustedKeys) == 0:
+		return
+	case err != nil:
+		message = fmt.Sprintf("%s is not signed", path)
+	case !info.Trusted:
+		message = fmt.Sprintf("%s is signed by an untrusted key %s", path, info.Fingerprint)
+	default:
+		return
+	}
+	failUnlessForced(message, "use --allow-untrusted to ignore", len(rootOptions.TrustedKeys) == 0 || allowUntrusted)
+}
+
+func verifyPluginSources(sources []*PluginSource, allowUntrusted bool) {
+	for _, source := range sources {
+		if source.Path != source.Origin {
+			checkArchiveSignature(source.Origin, allowUntrusted)
+		}
+	}
+}
+
+func verifyPlugin(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path := args.Get(0)
+	trustedKeys := append(slices.Clone(rootOptions.TrustedKeys), cmd.StringSlice("key")...)
+	info, err := verifyArchive(path, trustedKeys)
+	checkErr(err)
+	if !info.Trusted {
+		exitErr(fmt.Sprintf("Error: archive is signed by an untrusted key %s", info.Fingerprint))
+	}
+	fmt.Printf("Signature is valid, signed by trusted key %s\n", info.Fingerprint)
+	return nil
+}
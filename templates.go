@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const templateManifestFile = "template.yml"
+
+type TemplatePrompt struct {
+	Name    string   `yaml:"name"`
+	Message string   `yaml:"message"`
+	Type    string   `yaml:"type"` // input (default), confirm or select
+	Default string   `yaml:"default"`
+	Options []string `yaml:"options"`
+}
+
+type TemplateFile struct {
+	Source      string `yaml:"source"`
+	Destination string `yaml:"destination"`
+	Component   string `yaml:"component"` // render for each component of this type, or once per plugin if empty
+}
+
+type TemplateManifest struct {
+	Name    string           `yaml:"name"`
+	Prompts []TemplatePrompt `yaml:"prompts"`
+	Files   []TemplateFile   `yaml:"files"`
+}
+
+type TemplateData struct {
+	Author      string
+	Name        string
+	Plugin      string
+	Description string
+	Type        string
+}
+
+var (
+	activeTemplate    *TemplateManifest
+	templateVars      = map[string]interface{}{}
+	templateNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// templateOverlay serves files of a custom template set in place of the embedded plugin/ tree
+type templateOverlay struct {
+	custom fs.FS
+}
+
+func (overlay templateOverlay) Open(name string) (fs.File, error) {
+	if relPath, ok := strings.CutPrefix(name, "plugin/"); ok {
+		if f, err := overlay.custom.Open(relPath); err == nil {
+			return f, nil
+		}
+	}
+	return pluginsData.Open(name)
+}
+
+func isTemplateURL(source string) bool {
+	return strings.Contains(source, "://") || strings.HasPrefix(source, "git@")
+}
+
+// fetchTemplate clones a git template repository into the cache, or updates the cached copy.
+// A branch can be selected with url#branch
+func fetchTemplate(source string) string {
+	url, branch, _ := strings.Cut(source, "#")
+	cachePath := filepath.Join(SettingsPath(), "templates", templateNameRegex.ReplaceAllString(source, "_"))
+	var reference plumbing.ReferenceName
+	if branch != "" {
+		reference = plumbing.NewBranchReferenceName(branch)
+	}
+	if repo, err := git.PlainOpen(cachePath); err == nil {
+		worktree, err := repo.Worktree()
+		checkErr(err)
+		err = worktree.Pull(&git.PullOptions{ReferenceName: reference, SingleBranch: branch != "", Force: true})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			fmt.Printf("Warning: failed to update template %s, using cached copy: %s\n", source, err)
+		}
+		return cachePath
+	}
+	_, err := git.PlainClone(cachePath, false, &git.CloneOptions{
+		URL:           url,
+		ReferenceName: reference,
+		SingleBranch:  branch != "",
+		Depth:         1,
+	})
+	if err != nil {
+		os.RemoveAll(cachePath) // nolint:errcheck
+		exitErr(fmt.Sprintf("Error: failed to clone template %s: %s", source, err))
+	}
+	return cachePath
+}
+
+// loadTemplate switches scaffolding to a template set from a directory or git repository and parses its
+// template.yml. Files missing from the template set fall back to the embedded defaults
+func loadTemplate(source string, vars []string) {
+	if source == "" {
+		return
+	}
+	if isTemplateURL(source) {
+		source = fetchTemplate(source)
+	}
+	if !isDir(source) {
+		exitErr(fmt.Sprintf("Error: template directory %s does not exist", source))
+	}
+	templatesFS = templateOverlay{custom: os.DirFS(source)}
+	activeTemplate = &TemplateManifest{}
+	if data, err := os.ReadFile(filepath.Join(source, templateManifestFile)); err == nil {
+		if err := yaml.Unmarshal(data, activeTemplate); err != nil {
+			exitErr(fmt.Sprintf("Error: invalid %s: %s", templateManifestFile, err))
+		}
+	}
+	for _, variable := range vars {
+		key, value, ok := strings.Cut(variable, "=")
+		if !ok {
+			exitErr(fmt.Sprintf("Error: invalid variable %q, expected key=value", variable))
+		}
+		templateVars[key] = value
+	}
+}
+
+// askTemplatePrompts asks template prompts which were not provided via --var
+func askTemplatePrompts() {
+	if activeTemplate == nil {
+		return
+	}
+	for _, prompt := range activeTemplate.Prompts {
+		if _, ok := templateVars[prompt.Name]; ok {
+			continue
+		}
+		message := prompt.Message
+		if message == "" {
+			message = prompt.Name
+		}
+		switch prompt.Type {
+		case "confirm":
+			var answer bool
+			checkErr(survey.AskOne(&survey.Confirm{Message: message, Default: prompt.Default == "true"}, &answer))
+			templateVars[prompt.Name] = answer
+		case "select":
+			var answer string
+			selectPrompt := &survey.Select{Message: message, Options: prompt.Options}
+			if prompt.Default != "" {
+				selectPrompt.Default = prompt.Default
+			}
+			checkErr(survey.AskOne(selectPrompt, &answer))
+			templateVars[prompt.Name] = answer
+		default:
+			var answer string
+			checkErr(survey.AskOne(&survey.Input{Message: message, Default: prompt.Default}, &answer))
+			templateVars[prompt.Name] = answer
+		}
+	}
+}
+
+func renderString(text string, data TemplateData) string {
+	tmpl, err := template.New("").Funcs(templateFuncs).Parse(text)
+	checkErr(err)
+	buf := new(bytes.Buffer)
+	checkErr(tmpl.Execute(buf, data))
+	return buf.String()
+}
+
+// renderTemplateFiles writes extra files of the active template which match the component type
+func renderTemplateFiles(root string, data TemplateData) {
+	if activeTemplate == nil {
+		return
+	}
+	for _, file := range activeTemplate.Files {
+		if file.Component != data.Type {
+			continue
+		}
+		target, err := safeJoin(root, renderString(file.Destination, data))
+		checkErr(err)
+		source := path.Join("plugin", file.Source)
+		var content []byte
+		if strings.HasSuffix(source, ".tmpl") {
+			content = executeTemplate(source, data, false)
+		} else {
+			content, err = fs.ReadFile(templatesFS, source)
+			checkErr(err)
+		}
+		createIfNotExists(filepath.Dir(target), os.ModePerm)
+		checkErr(os.WriteFile(target, content, os.ModePerm))
+	}
+}
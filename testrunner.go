@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// getTestCommand returns the command running tests of a component from its repository checkout
+func getTestCommand(cmd *cli.Command, componentPath string, installType string) *exec.Cmd {
+	testsPath := filepath.Join(componentPath, "tests")
+	switch installType {
+	case "backend":
+		return exec.Command(cmd.String("python"), "-m", "pytest", testsPath)
+	case "admin", "store":
+		return exec.Command("npx", "vitest", "run", "--globals", "--root", componentPath)
+	}
+	return nil
+}
+
+func testPlugin(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path, err := filepath.Abs(args.Get(0))
+	checkErr(err)
+	manifest := readManifest(path).(map[string]interface{})
+	if !hasValidInstalls(manifest) {
+		exitErr("Error: manifest does not have valid installs")
+	}
+	types := cmd.StringSlice("type")
+	var failed []string
+	ran := 0
+	iterateInstallations(path, manifest, func(componentPath, componentName, installType string) {
+		if len(types) > 0 && !slices.Contains(types, installType) {
+			return
+		}
+		if !isDir(filepath.Join(componentPath, "tests")) {
+			return
+		}
+		testCmd := getTestCommand(cmd, componentPath, installType)
+		if testCmd == nil {
+			return
+		}
+		testCmd.Dir = askComponentDirectory(installType)
+		testCmd.Stdin = os.Stdin
+		testCmd.Stdout = os.Stdout
+		testCmd.Stderr = os.Stderr
+		fmt.Printf("Running tests of %s component %s: %s\n", installType, componentName, strings.Join(testCmd.Args, " "))
+		ran++
+		if err := testCmd.Run(); err != nil {
+			fmt.Printf("Tests of %s component %s failed: %s\n", installType, componentName, err)
+			failed = append(failed, installType+"/"+componentName)
+		}
+	})
+	if cmd.Bool("save") {
+		rootOptions.WriteToDisk()
+	}
+	switch {
+	case ran == 0:
+		fmt.Println("No component tests found")
+	case len(failed) > 0:
+		exitErr(fmt.Sprintf("Error: tests failed for %s", strings.Join(failed, ", ")))
+	default:
+		fmt.Printf("All tests passed for %d component(s)\n", ran)
+	}
+	return nil
+}
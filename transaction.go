@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+type stagedComponent struct {
+	Final    string
+	Staged   string
+	Backup   string
+	backedUp bool
+	swapped  bool
+}
+
+// InstallTransaction stages components next to their targets and swaps them in only when all of them are ready
+type InstallTransaction struct {
+	components []*stagedComponent
+	removals   []*stagedComponent
+	created    []string
+}
+
+func siblingPath(path string, suffix string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+"."+suffix)
+}
+
+func (tx *InstallTransaction) Stage(componentPath, finalPath string, isDev bool) error {
+	staged := siblingPath(finalPath, "staging")
+	component := &stagedComponent{
+		Final:  finalPath,
+		Staged: staged,
+		Backup: siblingPath(finalPath, "backup"),
+	}
+	tx.components = append(tx.components, component)
+	if err := os.MkdirAll(filepath.Dir(finalPath), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(staged); err != nil {
+		return err
+	}
+	if isDev {
+		return os.Symlink(componentPath, staged)
+	}
+	return copyDirectory(componentPath, staged)
+}
+
+// Remove schedules removal of a previously installed component which is no longer present
+func (tx *InstallTransaction) Remove(path string) {
+	tx.removals = append(tx.removals, &stagedComponent{Final: path, Backup: siblingPath(path, "backup")})
+}
+
+// Created records a path which didn't exist before the installation, to be removed on rollback
+func (tx *InstallTransaction) Created(path string) {
+	if !slices.Contains(tx.created, path) {
+		tx.created = append(tx.created, path)
+	}
+}
+
+func (component *stagedComponent) backup() error {
+	if _, err := os.Lstat(component.Final); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.RemoveAll(component.Backup); err != nil {
+		return err
+	}
+	if err := os.Rename(component.Final, component.Backup); err != nil {
+		return err
+	}
+	component.backedUp = true
+	return nil
+}
+
+func (tx *InstallTransaction) Commit() error {
+	for _, component := range tx.removals {
+		if err := component.backup(); err != nil {
+			return err
+		}
+	}
+	for _, component := range tx.components {
+		if err := component.backup(); err != nil {
+			return err
+		}
+		if err := os.Rename(component.Staged, component.Final); err != nil {
+			return err
+		}
+		component.swapped = true
+	}
+	for _, component := range slices.Concat(tx.removals, tx.components) {
+		if component.backedUp {
+			if err := os.RemoveAll(component.Backup); err != nil {
+				fmt.Println("Warning: could not remove", component.Backup+":", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (tx *InstallTransaction) Rollback() {
+	all := slices.Concat(tx.removals, tx.components)
+	slices.Reverse(all)
+	for _, component := range all {
+		if component.swapped {
+			if err := os.RemoveAll(component.Final); err != nil {
+				fmt.Println("Warning: could not remove", component.Final+":", err)
+			}
+		}
+		if component.backedUp {
+			if err := os.Rename(component.Backup, component.Final); err != nil {
+				fmt.Println("Warning: could not restore", component.Final+":", err)
+			}
+		}
+		if component.Staged != "" {
+			if err := os.RemoveAll(component.Staged); err != nil {
+				fmt.Println("Warning: could not remove", component.Staged+":", err)
+			}
+		}
+	}
+	for _, path := range slices.Backward(tx.created) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			fmt.Println("Warning: could not remove", path+":", err)
+		}
+	}
+}
+
+// installComponents installs all plugin components atomically, removing the previously installed paths not
+// present in the new version. On any error, the previous state is restored
+func installComponents(source *PluginSource, isDev bool, save bool, previous []string) []InstalledComponent {
+	tx := &InstallTransaction{}
+	for _, component := range resolvePluginComponents(source.Path, source.Manifest) {
+		// organization directory, and for backend components its __init__.py, are created before staging
+		orgPath := filepath.Dir(component.Final)
+		paths := []string{orgPath}
+		if component.Type == "backend" {
+			paths = append(paths, filepath.Join(orgPath, "__init__.py"))
+		}
+		for _, path := range paths {
+			if _, err := os.Lstat(path); os.IsNotExist(err) {
+				tx.Created(path)
+			}
+		}
+	}
+	var err error
+	components := pluginActionBase(source.Path, save, func(componentPath, finalPath string) {
+		if err == nil {
+			err = tx.Stage(componentPath, finalPath, isDev)
+		}
+	})
+	for _, path := range previous {
+		if !slices.ContainsFunc(components, func(component InstalledComponent) bool {
+			return component.Path == path
+		}) {
+			tx.Remove(path)
+		}
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		tx.Rollback()
+		exitErr(fmt.Sprintf("Error: installation of %s failed, changes reverted: %s", pluginKey(source.Manifest), err))
+	}
+	return components
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blang/semver"
+	"github.com/urfave/cli/v3"
+)
+
+func upgradePlugin(ctx context.Context, cmd *cli.Command) error {
+	args := cmd.Args()
+	if args.Len() < 1 {
+		return cli.ShowSubcommandHelp(cmd)
+	}
+	path := args.Get(0)
+	save := cmd.Bool("save")
+	force := cmd.Bool("force")
+	source, cleanup := openPluginSource(path)
+	defer cleanup()
+	key := pluginKey(source.Manifest)
+	registry := &PluginRegistry{}
+	registry.Load()
+	installed, ok := registry.Plugins[key]
+	if !ok {
+		exitErr(fmt.Sprintf("Error: plugin %s is not installed, use plugin install instead", key))
+	}
+	newVersionString, _ := source.Manifest["version"].(string)
+	newVersion, err := semver.ParseTolerant(newVersionString)
+	checkErr(err)
+	oldVersion, err := semver.ParseTolerant(installed.Version)
+	checkErr(err)
+	if newVersion.LTE(oldVersion) {
+		message := fmt.Sprintf(
+			"%s %s is installed, which is not older than %s",
+			key,
+			oldVersion,
+			newVersion,
+		)
+		failUnlessForced(message, "use --force to reinstall", force)
+	}
+	available, cleanupDependencies := collectPluginSources(cmd.StringSlice("deps"))
+	defer cleanupDependencies()
+	order, err := resolveInstallOrder(source, available, registry)
+	checkErr(err)
+	verifyPluginSources(order, cmd.Bool("allow-untrusted"))
+	checkDependentConstraints(order, registry, force)
+	for _, plugin := range order[:len(order)-1] {
+		fmt.Println("Installing dependency", pluginKey(plugin.Manifest))
+		registry.Plugins[pluginKey(plugin.Manifest)] = installSinglePlugin(plugin, false, save, force)
+		registry.WriteToDisk()
+	}
+	enforceBitcartConstraint(source.Manifest, force)
+	var previous []string
+	for _, component := range installed.Components {
+		previous = append(previous, component.Path)
+	}
+	components := installComponents(source, false, save, previous)
+	registry.Plugins[key] = newInstalledPlugin(source, false, components)
+	registry.WriteToDisk()
+	fmt.Printf("Upgraded %s from %s to %s\n", key, oldVersion, newVersion)
+	return nil
+}
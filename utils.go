@@ -8,8 +8,10 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
@@ -30,6 +32,14 @@ func exitErr(err string) {
 	os.Exit(1)
 }
 
+// failUnlessForced exits with the message and a hint on how to override the check, or only warns if forced
+func failUnlessForced(message string, hint string, force bool) {
+	if !force {
+		exitErr(fmt.Sprintf("Error: %s (%s)", message, hint))
+	}
+	fmt.Println("Warning:", message)
+}
+
 func checkErr(err error) {
 	if err != nil {
 		exitErr("Error: " + err.Error())
@@ -94,7 +104,41 @@ func parseVersionFromURL(url string) string {
 	return parts[len(parts)-2]
 }
 
+// getEmbeddedSchema returns the bundled copy of a bitcart-hosted schema, if this version is known.
+// Files in plugin/schemas must match the published schemas exactly, so that offline and online validation agree
+func getEmbeddedSchema(url string) []byte {
+	if !strings.HasPrefix(url, schemaBaseURL) {
+		return nil
+	}
+	data, err := pluginsData.ReadFile(
+		path.Join("plugin/schemas", parseVersionFromURL(url), "plugin.schema.json"),
+	)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func downloadSchema(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
 func prepareSchema(url string) *jsonschema.Schema {
+	if data := getEmbeddedSchema(url); data != nil {
+		compiler := jsonschema.NewCompiler()
+		checkErr(compiler.AddResource(url, bytes.NewReader(data)))
+		sch, err := compiler.Compile(url)
+		checkErr(err)
+		return sch
+	}
 	cacheDir := getCacheDir()
 	schemaPath := filepath.Join(cacheDir, "plugin.schema.json")
 	versionFile := filepath.Join(cacheDir, "schema.version")
@@ -106,29 +150,120 @@ func prepareSchema(url string) *jsonschema.Schema {
 		) > time.Since(
 			time.Now(),
 		) || (versionErr == nil && string(version) != schemaVersion) {
-		resp, err := http.Get(url)
-		checkErr(err)
-		defer resp.Body.Close()
-		data, err := io.ReadAll(resp.Body)
-		checkErr(err)
-		checkErr(os.WriteFile(schemaPath, data, os.ModePerm))
-		checkErr(
-			os.WriteFile(
-				filepath.Join(cacheDir, "schema.version"),
-				[]byte(schemaVersion),
-				os.ModePerm,
-			),
-		)
+		data, err := downloadSchema(url)
+		switch {
+		case err == nil:
+			checkErr(os.WriteFile(schemaPath, data, os.ModePerm))
+			checkErr(
+				os.WriteFile(
+					filepath.Join(cacheDir, "schema.version"),
+					[]byte(schemaVersion),
+					os.ModePerm,
+				),
+			)
+		case versionErr == nil && string(version) == schemaVersion && exists(schemaPath):
+			// air-gapped hosts keep validating against the last downloaded copy
+			fmt.Fprintln(os.Stderr, "Warning: could not refresh schema, using cached copy:", err)
+		default:
+			exitErr(fmt.Sprintf("Error: schema %s is not bundled and could not be downloaded: %s", schemaVersion, err))
+		}
 	}
 	sch, err := jsonschema.Compile(schemaPath)
 	checkErr(err)
 	return sch
 }
 
+// expandManifestTemplate replaces {{name}}, {{author}} and {{version}} placeholders
+func expandManifestTemplate(manifest map[string]interface{}, version string, text string) string {
+	return strings.NewReplacer(
+		"{{name}}", fmt.Sprint(manifest["name"]),
+		"{{author}}", fmt.Sprint(manifest["author"]),
+		"{{version}}", version,
+	).Replace(text)
+}
+
 func readManifest(path string) interface{} {
 	manifestPath := filepath.Join(path, "manifest.json")
 	data, err := os.ReadFile(manifestPath)
 	checkErr(err)
+	return parseManifest(data)
+}
+
+var manifestKeyOrder = []string{
+	"$schema", "name", "author", "version", "description", "constraints", "dependencies", "files", "installs",
+}
+var installKeyOrder = []string{"type", "path"}
+
+func orderKeys(data map[string]interface{}, order []string) []string {
+	var keys []string
+	for _, key := range order {
+		if _, ok := data[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range sortedKeys(data) {
+		if !slices.Contains(order, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func writeOrderedJSON(buf *bytes.Buffer, value interface{}, order []string, indent string) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		keys := orderKeys(value, order)
+		for i, key := range keys {
+			buf.WriteString(indent + "  " + strings.TrimSpace(jsonEncode(key)) + ": ")
+			var childOrder []string
+			if indent == "" && key == "installs" {
+				childOrder = installKeyOrder
+			}
+			writeOrderedJSON(buf, value[key], childOrder, indent+"  ")
+			if i < len(keys)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	case []interface{}:
+		if len(value) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, item := range value {
+			buf.WriteString(indent + "  ")
+			writeOrderedJSON(buf, item, order, indent+"  ")
+			if i < len(value)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "]")
+	default:
+		buf.WriteString(strings.TrimSpace(jsonEncode(value)))
+	}
+}
+
+// formatManifest encodes the manifest with 2-space indentation and a canonical key order
+func formatManifest(manifest map[string]interface{}) []byte {
+	buf := new(bytes.Buffer)
+	writeOrderedJSON(buf, manifest, manifestKeyOrder, "")
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+func writeManifest(path string, manifest map[string]interface{}) {
+	checkErr(os.WriteFile(filepath.Join(path, "manifest.json"), formatManifest(manifest), os.ModePerm))
+}
+
+func parseManifest(data []byte) interface{} {
 	var manifest interface{}
 	checkErr(json.Unmarshal(data, &manifest))
 	return manifest
@@ -191,7 +326,8 @@ func getComponentConfigEntry(componentType string) *string {
 
 type RejectByNameFunc func(path string) bool
 
-func rejectGitignored(targets []string) (RejectByNameFunc, error) {
+// rejectGitignored matches paths ignored by .gitignore files of the targets, with extra patterns applied last
+func rejectGitignored(targets []string, extra []gitignore.Pattern) (RejectByNameFunc, error) {
 	var patterns []gitignore.Pattern
 	fs := osfs.New("/")
 	for _, target := range targets {
@@ -205,6 +341,7 @@ func rejectGitignored(targets []string) (RejectByNameFunc, error) {
 		}
 		patterns = append(patterns, patternsNow...)
 	}
+	patterns = append(patterns, extra...)
 	matcher := gitignore.NewMatcher(patterns)
 	return func(filename string) bool {
 		isDir := isDir(filename)
@@ -216,6 +353,65 @@ func rejectGitignored(targets []string) (RejectByNameFunc, error) {
 	}, nil
 }
 
+func readIgnoreFile(path string) ([]gitignore.Pattern, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	domain, err := pathToArray(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	var patterns []gitignore.Pattern
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if isBlank(line) || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, domain))
+	}
+	return patterns, nil
+}
+
+// packageExcluder combines .gitignore (unless noStrip is set) and .bitcartignore rules. If manifest has
+// a files list, only files matching it (and the manifest itself) are included
+func packageExcluder(path string, manifest map[string]interface{}, noStrip bool) RejectByNameFunc {
+	var targets []string
+	if !noStrip {
+		targets = []string{path}
+	}
+	extra, err := readIgnoreFile(filepath.Join(path, ".bitcartignore"))
+	checkErr(err)
+	isIgnored, err := rejectGitignored(targets, extra)
+	checkErr(err)
+	files, ok := manifest["files"].([]interface{})
+	if !ok {
+		return isIgnored
+	}
+	domain, err := pathToArray(path)
+	checkErr(err)
+	var allowed []gitignore.Pattern
+	for _, file := range files {
+		allowed = append(allowed, gitignore.ParsePattern(file.(string), domain))
+	}
+	allowMatcher := gitignore.NewMatcher(allowed)
+	manifestPath, err := pathToArray(filepath.Join(path, "manifest.json"))
+	checkErr(err)
+	return func(filename string) bool {
+		if isIgnored(filename) {
+			return true
+		}
+		p, err := pathToArray(filename)
+		if err != nil || isDir(filename) || slices.Equal(p, manifestPath) {
+			return false
+		}
+		return !allowMatcher.Match(p, false)
+	}
+}
+
 func isDir(filename string) bool {
 	file, err := os.Open(filename)
 	if err != nil {
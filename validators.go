@@ -1,10 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 )
 
 func validateFileExists(path string) bool {
@@ -47,10 +50,217 @@ func frontendDirectoryValidator(val interface{}) error {
 	return nil
 }
 
-func validateFrontend(componentType string, path string) {
+func componentNameValidator(val interface{}) error {
+	name := val.(string)
+	if name == "" || name == "." || strings.Contains(name, "..") || strings.ContainsAny(name, `/\`) {
+		return errors.New("Component name must be a directory name without path separators or ..")
+	}
+	return nil
+}
+
+var pageNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+func pageNameValidator(val interface{}) error {
+	if !pageNameRegex.MatchString(val.(string)) {
+		return errors.New("Page name must start with a letter or digit and contain only letters, digits, - and _")
+	}
+	return nil
+}
+
+func validateBackend(report *ValidationReport, root string, path string, location string) {
+	if !validateFileExists(filepath.Join(path, "plugin.py")) {
+		report.Error(
+			"missing-file",
+			relativeToPlugin(root, path),
+			location,
+			"Backend component does not include plugin.py",
+			"Create plugin.py with a Plugin(BasePlugin) class",
+		)
+	}
+}
+
+func validateFrontend(report *ValidationReport, root string, componentType string, path string, location string) {
 	for _, file := range []string{"index.js", "package.json", "config/index.js"} {
 		if !validateFileExists(filepath.Join(path, file)) {
-			log.Fatalf("Plugin's %s component %s does not include %s", componentType, path, file)
+			report.Error(
+				"missing-file",
+				relativeToPlugin(root, path),
+				location,
+				fmt.Sprintf("The %s component does not include %s", componentType, file),
+				fmt.Sprintf("Create %s, i.e. from the plugin init templates", file),
+			)
 		}
 	}
 }
+
+// hasValidInstalls checks installs structure, so that iterateInstallations can be used safely
+func hasValidInstalls(manifest map[string]interface{}) bool {
+	installs, ok := manifest["installs"].([]interface{})
+	if !ok {
+		return false
+	}
+	for _, installData := range installs {
+		installData, ok := installData.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := installData["path"].(string); !ok {
+			return false
+		}
+		if _, ok := installData["type"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	pluginClassRegex = regexp.MustCompile(`(?m)^class\s+Plugin\s*\(\s*BasePlugin\s*\)\s*:`)
+	pluginNameRegex  = regexp.MustCompile(`(?m)^\s+name\s*=\s*["']([^"']*)["']`)
+)
+
+// isInsideRoot checks that the component path, with symlinks resolved, doesn't escape the plugin root
+func isInsideRoot(root string, path string) bool {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return false
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolvedRoot
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+	}
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func validateInstallPaths(report *ValidationReport, root string, manifest map[string]interface{}) bool {
+	valid := true
+	seen := map[string]int{}
+	for i, installData := range manifest["installs"].([]interface{}) {
+		installData := installData.(map[string]interface{})
+		location := fmt.Sprintf("/installs/%d/path", i)
+		installPath := installData["path"].(string)
+		if filepath.IsAbs(installPath) || !isInsideRoot(root, filepath.Join(root, installPath)) {
+			report.Error(
+				"path-outside-root",
+				"manifest.json",
+				location,
+				fmt.Sprintf("Component path %s is outside of the plugin directory", installPath),
+				"Use a path relative to the plugin root, i.e. src/backend/<name>",
+			)
+			valid = false
+			continue
+		}
+		key := installData["type"].(string) + "/" + filepath.Base(installPath)
+		if previous, ok := seen[key]; ok {
+			report.Error(
+				"duplicate-component",
+				"manifest.json",
+				location,
+				fmt.Sprintf("Component %s is already defined in /installs/%d", key, previous),
+				"Rename one of the components, they would be installed to the same directory",
+			)
+			continue
+		}
+		seen[key] = i
+	}
+	return valid
+}
+
+func validateBackendSemantics(report *ValidationReport, root string, path string, name string) {
+	pluginPath := filepath.Join(path, "plugin.py")
+	data, err := os.ReadFile(pluginPath)
+	if err != nil {
+		return
+	}
+	file := relativeToPlugin(root, pluginPath)
+	classLocation := pluginClassRegex.FindIndex(data)
+	if classLocation == nil {
+		report.Error(
+			"plugin-class",
+			file,
+			"",
+			"plugin.py does not define a Plugin(BasePlugin) class",
+			"Define class Plugin(BasePlugin) as in the plugin init template",
+		)
+		return
+	}
+	match := pluginNameRegex.FindSubmatch(data[classLocation[1]:])
+	if match == nil {
+		report.Error(
+			"plugin-name",
+			file,
+			"",
+			"Plugin class does not define a name",
+			fmt.Sprintf("Add name = %q to the Plugin class", name),
+		)
+		return
+	}
+	if string(match[1]) != name {
+		report.Error(
+			"plugin-name",
+			file,
+			"",
+			fmt.Sprintf("Plugin name %q does not match component directory %q", match[1], name),
+			fmt.Sprintf("Set name = %q in the Plugin class", name),
+		)
+	}
+}
+
+func validateFrontendSemantics(report *ValidationReport, root string, path string, author string, name string) {
+	expectedName := "@" + author + "/" + name
+	packagePath := filepath.Join(path, "package.json")
+	if data, err := os.ReadFile(packagePath); err == nil {
+		var packageData map[string]interface{}
+		if err := json.Unmarshal(data, &packageData); err != nil {
+			report.Error(
+				"package-json",
+				relativeToPlugin(root, packagePath),
+				"",
+				fmt.Sprintf("Invalid package.json: %s", err),
+				"Fix JSON syntax",
+			)
+		} else if packageData["name"] != expectedName {
+			report.Error(
+				"package-name",
+				relativeToPlugin(root, packagePath),
+				"/name",
+				fmt.Sprintf("Package name %v does not match %s", packageData["name"], expectedName),
+				fmt.Sprintf("Set name to %q", expectedName),
+			)
+		}
+	}
+	configPath := filepath.Join(path, "config/index.js")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return
+	}
+	file := relativeToPlugin(root, configPath)
+	namePattern := regexp.MustCompile(`name:\s*["']` + regexp.QuoteMeta(expectedName) + `["']`)
+	if !namePattern.Match(data) {
+		report.Error(
+			"config-name",
+			file,
+			"",
+			fmt.Sprintf("config/index.js does not set name to %s", expectedName),
+			fmt.Sprintf("Set name: %q in the exported config", expectedName),
+		)
+	}
+	alias := "@" + author + "-" + name
+	aliasPattern := regexp.MustCompile(`["']` + regexp.QuoteMeta(alias) + `["']\s*:`)
+	if !aliasPattern.Match(data) {
+		report.Error(
+			"config-alias",
+			file,
+			"",
+			fmt.Sprintf("config/index.js does not define the %s alias", alias),
+			fmt.Sprintf("Add %q: \"/\" to aliases", alias),
+		)
+	}
+}